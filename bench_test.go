@@ -0,0 +1,135 @@
+package configo_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/PaesslerAG/jsonpath"
+	"github.com/affanshahid/configo"
+)
+
+// benchDir holds a configuration with a few hundred keys nested three levels deep,
+// all benchmarks load it so their results can be compared
+var benchDir = func() fstest.MapFS {
+	var yml strings.Builder
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(&yml, "service%d:\n", i)
+		for j := 0; j < 10; j++ {
+			fmt.Fprintf(&yml, "  group%d:\n", j)
+			for k := 0; k < 5; k++ {
+				fmt.Fprintf(&yml, "    key%d: value-%d-%d-%d\n", k, i, j, k)
+			}
+		}
+	}
+
+	return fstest.MapFS{"default.yml": {Data: []byte(yml.String())}}
+}()
+
+const benchPath = "service7.group3.key4"
+
+// BenchmarkJSONPathGet measures how lookups were done before paths were indexed
+func BenchmarkJSONPathGet(b *testing.B) {
+	config, err := configo.NewConfig(benchDir)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	err = config.Initialize()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	settings := config.AllSettings()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err = jsonpath.Get(benchPath, settings)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetString(b *testing.B) {
+	config, err := configo.NewConfig(benchDir)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	err = config.Initialize()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err = config.GetString(benchPath)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetStringParallel(b *testing.B) {
+	config, err := configo.NewConfig(benchDir)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	err = config.Initialize()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, err := config.GetString(benchPath)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkQuery(b *testing.B) {
+	config, err := configo.NewConfig(benchDir)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	err = config.Initialize()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err = config.Query("$.service7.group3[*]")
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkInitialize(b *testing.B) {
+	config, err := configo.NewConfig(benchDir)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	err = config.Initialize()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err = config.Initialize()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
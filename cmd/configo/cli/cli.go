@@ -0,0 +1,340 @@
+// Package cli implements the configo command. Services build their own command with `Run`
+// to pass options the configurations need, such as the migrations of `configo.WithMigration`:
+//
+//	func main() {
+//		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr, appconfig.Migrations...))
+//	}
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/affanshahid/configo"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const usage = `Usage:
+  configo dump    [flags]
+  configo get     [flags] <path>
+  configo explain [flags] <path>
+  configo diff    [flags] --left <spec> --right <spec>
+  configo lint    [flags]
+  configo convert [flags] <input> <output>
+  configo migrate [flags] <input> [output]
+  configo gen     [flags] --package <name>
+
+Flags:
+`
+
+// errUsage signals that the usage should be printed
+var errUsage = errors.New("invalid usage")
+
+// errDifferent signals that diff found differences
+var errDifferent = errors.New("configurations differ")
+
+// errIssues signals that lint found issues
+var errIssues = errors.New("lint issues found")
+
+// findingCommands exit with 1 when they find differences or issues,
+// so their errors exit with 2 like diff(1). Errors of the other commands exit with 1
+var findingCommands = map[string]bool{
+	"diff": true,
+	"lint": true,
+}
+
+type command func(opts *options, args []string, stdout io.Writer) error
+
+var commands = map[string]command{
+	"dump":    dump,
+	"get":     get,
+	"explain": explain,
+	"diff":    diff,
+	"lint":    lint,
+	"convert": convert,
+	"migrate": migrate,
+	"gen":     gen,
+}
+
+type options struct {
+	dir, deployment, instance, hostname, format string
+	left, right                                 string
+	deployments, hostnames                      string
+	pkg, schema, out                            string
+
+	// config holds the options given to `Run`
+	config []configo.ConfigOption
+	// environment holds the options of the environment flags which were set
+	environment []configo.ConfigOption
+}
+
+// Run executes the command line and returns the exit code,
+// the options are passed to `configo.NewConfig` before the ones of the flags
+func Run(args []string, stdout, stderr io.Writer, configOpts ...configo.ConfigOption) int {
+	flags, opts := newFlagSet(stderr)
+	opts.config = configOpts
+
+	if len(args) == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	cmd, found := commands[args[0]]
+	if !found {
+		fmt.Fprintf(stderr, "configo: unknown command %q\n", args[0])
+		flags.Usage()
+		return 2
+	}
+
+	positional, err := parseArgs(flags, args[1:])
+	if err != nil {
+		return 2
+	}
+
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "deployment":
+			opts.environment = append(opts.environment, configo.WithDeployment(opts.deployment))
+		case "instance":
+			opts.environment = append(opts.environment, configo.WithInstance(opts.instance))
+		case "hostname":
+			opts.environment = append(opts.environment, configo.WithHostname(opts.hostname))
+		}
+	})
+
+	err = cmd(opts, positional, stdout)
+
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errDifferent), errors.Is(err, errIssues):
+		return 1
+	case errors.Is(err, errUsage):
+		flags.Usage()
+		return 2
+	default:
+		fmt.Fprintf(stderr, "configo: %v\n", err)
+		if findingCommands[args[0]] {
+			return 2
+		}
+		return 1
+	}
+}
+
+func newFlagSet(stderr io.Writer) (*flag.FlagSet, *options) {
+	opts := &options{}
+
+	flags := flag.NewFlagSet("configo", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&opts.dir, "dir", ".", "directory containing the config files")
+	flags.StringVar(&opts.deployment, "deployment", "", `deployment label (default "dev")`)
+	flags.StringVar(&opts.instance, "instance", "", "instance id")
+	flags.StringVar(&opts.hostname, "hostname", "", "hostname (default os.Hostname())")
+	flags.StringVar(&opts.format, "format", "yaml", "output format of dump, get and convert to stdout")
+	flags.StringVar(&opts.left, "left", "", "environment of the left side of diff, i.e deployment=staging")
+	flags.StringVar(&opts.right, "right", "", "environment of the right side of diff, i.e deployment=production,instance=2")
+	flags.StringVar(&opts.deployments, "deployments", "", "comma separated known deployments for lint (default the deployment)")
+	flags.StringVar(&opts.hostnames, "hostnames", "", "comma separated known hostnames for lint (default the hostname)")
+	flags.StringVar(&opts.pkg, "package", "", "package of the code generated by gen")
+	flags.StringVar(&opts.schema, "schema", "", "JSON Schema refining the types inferred by gen")
+	flags.StringVar(&opts.out, "out", "", "file written by gen (default stdout)")
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	return flags, opts
+}
+
+// parseArgs parses flags placed before and after positional arguments
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		err := flags.Parse(args)
+		if err != nil {
+			return nil, err
+		}
+
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// configOptions returns a copy of the options given to `Run`
+func (opts *options) configOptions() []configo.ConfigOption {
+	return append([]configo.ConfigOption{}, opts.config...)
+}
+
+// load initializes the configurations with only the environment flags which were set,
+// leaving the rest to the defaults of `configo.NewConfig`
+func load(opts *options, extra ...configo.ConfigOption) (*configo.Config, error) {
+	configOpts := append(append(opts.configOptions(), opts.environment...), extra...)
+
+	config, err := configo.NewConfig(os.DirFS(opts.dir), configOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = config.Initialize()
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// dump prints the merged configurations
+func dump(opts *options, args []string, stdout io.Writer) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	config, err := load(opts)
+	if err != nil {
+		return err
+	}
+
+	_, err = config.WriteTo(stdout, opts.format)
+	return err
+}
+
+// get prints the value at a single path, scalars are printed as is
+func get(opts *options, args []string, stdout io.Writer) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	config, err := load(opts)
+	if err != nil {
+		return err
+	}
+
+	value, err := lookup(config, args[0])
+	if err != nil {
+		return err
+	}
+
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return write(stdout, value, opts.format)
+	}
+
+	_, err = fmt.Fprintln(stdout, value)
+	return err
+}
+
+// explain prints the value at a single path along with every layer which set it
+func explain(opts *options, args []string, stdout io.Writer) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+
+	config, err := load(opts)
+	if err != nil {
+		return err
+	}
+
+	path := args[0]
+
+	value, err := lookup(config, path)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "%s = %s\n", path, out)
+
+	if config.IsSecret(path) {
+		fmt.Fprintln(stdout, "secret: yes")
+	}
+
+	origins := config.Origins(path)
+	if len(origins) == 0 {
+		fmt.Fprintln(stdout, "source: unknown")
+		return nil
+	}
+
+	fmt.Fprintf(stdout, "source: %s\n", origins[len(origins)-1])
+	for i := len(origins) - 2; i >= 0; i-- {
+		fmt.Fprintf(stdout, "overrides: %s\n", origins[i])
+	}
+
+	return nil
+}
+
+// lookup returns the masked value at the path
+func lookup(config *configo.Config, path string) (interface{}, error) {
+	keys, err := configo.SplitPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !config.Has(path) {
+		return nil, fmt.Errorf("unknown key: %s", path)
+	}
+
+	var value interface{} = config.Redacted()
+	for _, key := range keys {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[key]
+		case []interface{}:
+			i, _ := strconv.Atoi(key)
+			value = v[i]
+		default:
+			// a secret above the path was masked
+			return value, nil
+		}
+	}
+
+	return value, nil
+}
+
+func write(w io.Writer, value interface{}, format string) error {
+	var buf bytes.Buffer
+
+	switch strings.ToLower(format) {
+	case "yaml", "yml":
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		err := enc.Encode(value)
+		if err != nil {
+			return err
+		}
+	case "json":
+		enc := json.NewEncoder(&buf)
+		enc.SetIndent("", "  ")
+		err := enc.Encode(value)
+		if err != nil {
+			return err
+		}
+	case "toml":
+		err := toml.NewEncoder(&buf).Encode(value)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644)
+		assert.Nilf(t, err, "err should be nil")
+	}
+	return dir
+}
+
+func TestCLI(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"default.yml":           "db:\n  host: localhost\n  port: 5432\n  password: hunter2\nsecret:\n  - db.password\n",
+		"production.yml":        "db:\n  port: 5433\n",
+		"web1-production-2.yml": "db:\n  host: web1.internal\n",
+	})
+
+	cases := []struct {
+		name string
+		args []string
+		code int
+		out  string
+	}{
+		{
+			"dump",
+			[]string{"dump", "--dir", dir, "--deployment", "production", "--instance", "2", "--hostname", "web1.example.com"},
+			0,
+			"db:\n  host: web1.internal\n  password: '******'\n  port: 5433\n",
+		},
+		{
+			"dump json",
+			[]string{"dump", "--dir", dir, "--format", "json", "--hostname", "web1.example.com"},
+			0,
+			"{\n  \"db\": {\n    \"host\": \"localhost\",\n    \"password\": \"******\",\n    \"port\": 5432\n  }\n}\n",
+		},
+		{
+			"dump toml",
+			[]string{"dump", "--dir", dir, "--format", "toml", "--deployment", "production"},
+			0,
+			"[db]\nhost = 'localhost'\npassword = '******'\nport = 5433\n\n",
+		},
+		{
+			"get",
+			[]string{"get", "db.port", "--dir", dir, "--deployment", "production"},
+			0,
+			"5433\n",
+		},
+		{
+			"get secret",
+			[]string{"get", "--dir", dir, "db.password"},
+			0,
+			"******\n",
+		},
+		{
+			"explain",
+			[]string{"explain", "--dir", dir, "--deployment", "production", "db.port"},
+			0,
+			"db.port = 5433\nsource: production.yml:2:3\noverrides: default.yml:3:3\n",
+		},
+		{
+			"explain secret",
+			[]string{"explain", "--dir", dir, "db.password"},
+			0,
+			"db.password = \"******\"\nsecret: yes\nsource: default.yml:4:3\n",
+		},
+		{"unknown key", []string{"get", "--dir", dir, "db.user"}, 1, ""},
+		{"missing path", []string{"get", "--dir", dir}, 2, ""},
+		{"unknown command", []string{"set"}, 2, ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := Run(tc.args, &stdout, &stderr)
+
+			assert.Equal(t, tc.code, code, stderr.String())
+			assert.Equal(t, tc.out, stdout.String())
+		})
+	}
+}
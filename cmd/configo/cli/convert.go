@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/affanshahid/configo"
+)
+
+// convert rewrites a configuration file in the format of the output extension,
+// an output of `-` writes to stdout in the format of the format flag.
+// Comments are not preserved
+func convert(opts *options, args []string, stdout io.Writer) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+
+	input, output := args[0], args[1]
+
+	in, err := os.ReadFile(input)
+	if err != nil {
+		return err
+	}
+
+	to := opts.format
+	if output != "-" {
+		to = filepath.Ext(output)
+	}
+
+	out, err := configo.Convert(in, filepath.Ext(input), to)
+	if err != nil {
+		return err
+	}
+
+	if output == "-" {
+		_, err = stdout.Write(out)
+		return err
+	}
+
+	return os.WriteFile(output, out, 0o644)
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvert(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"default.json": `{"db": {"host": "localhost", "port": 5432}}`,
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"convert", filepath.Join(dir, "default.json"), filepath.Join(dir, "default.yaml")}, &stdout, &stderr)
+	assert.Equal(t, 0, code, stderr.String())
+
+	out, err := os.ReadFile(filepath.Join(dir, "default.yaml"))
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, "db:\n  host: localhost\n  port: 5432\n", string(out))
+
+	code = Run([]string{"convert", "--format", "toml", filepath.Join(dir, "default.yaml"), "-"}, &stdout, &stderr)
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Equal(t, "[db]\nhost = 'localhost'\nport = 5432\n\n", stdout.String())
+
+	code = Run([]string{"convert", filepath.Join(dir, "default.json")}, &stdout, &stderr)
+	assert.Equal(t, 2, code)
+}
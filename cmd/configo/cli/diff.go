@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/affanshahid/configo"
+)
+
+// side is one of the two configurations compared by diff
+type side struct {
+	spec   string
+	config *configo.Config
+	leaves map[string]leaf
+}
+
+// leaf is a single masked value along with its location in the tree
+type leaf struct {
+	keys  []string
+	value interface{}
+}
+
+// diff loads the configurations of both environments from the same directory
+// and prints the keys which were added, removed or changed
+func diff(opts *options, args []string, stdout io.Writer) error {
+	if len(args) != 0 || opts.left == "" || opts.right == "" {
+		return errUsage
+	}
+
+	left, err := loadSide(opts, opts.left)
+	if err != nil {
+		return err
+	}
+
+	right, err := loadSide(opts, opts.right)
+	if err != nil {
+		return err
+	}
+
+	paths := map[string]struct{}{}
+	for path := range left.leaves {
+		paths[path] = struct{}{}
+	}
+	for path := range right.leaves {
+		paths[path] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	var lines []string
+	for _, path := range sorted {
+		l, inLeft := left.leaves[path]
+		r, inRight := right.leaves[path]
+
+		switch {
+		case !inRight:
+			lines = append(lines, fmt.Sprintf("- %s = %s  (%s)", path, formatValue(l.value), left.source(path)))
+		case !inLeft:
+			lines = append(lines, fmt.Sprintf("+ %s = %s  (%s)", path, formatValue(r.value), right.source(path)))
+		case !equal(left, right, l, r, path):
+			lines = append(lines, fmt.Sprintf(
+				"~ %s = %s -> %s  (%s -> %s)",
+				path, formatValue(l.value), formatValue(r.value), left.source(path), right.source(path),
+			))
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(stdout, "--- %s\n+++ %s\n", left.spec, right.spec)
+	for _, line := range lines {
+		fmt.Fprintln(stdout, line)
+	}
+
+	return errDifferent
+}
+
+// loadSide loads the configurations for a spec such as `deployment=production,instance=2`,
+// environment flags which were set apply to both sides unless the spec overrides them
+func loadSide(opts *options, spec string) (*side, error) {
+	var extra []configo.ConfigOption
+
+	for _, pair := range strings.Split(spec, ",") {
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid environment %q, expected key=value pairs", spec)
+		}
+
+		switch strings.TrimSpace(key) {
+		case "deployment":
+			extra = append(extra, configo.WithDeployment(value))
+		case "instance":
+			extra = append(extra, configo.WithInstance(value))
+		case "hostname":
+			extra = append(extra, configo.WithHostname(value))
+		default:
+			return nil, fmt.Errorf("invalid environment %q, unknown key %q", spec, key)
+		}
+	}
+
+	config, err := load(opts, extra...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", spec, err)
+	}
+
+	s := &side{spec: spec, config: config, leaves: map[string]leaf{}}
+	s.flatten(config.Redacted(), nil)
+
+	return s, nil
+}
+
+// flatten collects the leaves of the tree under their paths, empty maps and slices are leaves
+func (s *side) flatten(value interface{}, keys []string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) > 0 {
+			for key, val := range v {
+				s.flatten(val, append(keys[:len(keys):len(keys)], key))
+			}
+			return
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			for i, val := range v {
+				s.flatten(val, append(keys[:len(keys):len(keys)], strconv.Itoa(i)))
+			}
+			return
+		}
+	}
+
+	s.leaves[configo.JoinPath(keys...)] = leaf{keys, value}
+}
+
+// source returns the position which defined the path
+func (s *side) source(path string) string {
+	pos, found := s.config.Position(path)
+	if !found {
+		return "unknown"
+	}
+
+	return pos.String()
+}
+
+// equal compares two leaves, secrets are masked in the leaves so their actual values are compared
+func equal(left, right *side, l, r leaf, path string) bool {
+	if !left.config.IsSecret(path) && !right.config.IsSecret(path) {
+		return reflect.DeepEqual(l.value, r.value)
+	}
+
+	lv, lerr := left.config.Get(path)
+	rv, rerr := right.config.Get(path)
+	return lerr == nil && rerr == nil && reflect.DeepEqual(lv, rv)
+}
+
+func formatValue(value interface{}) string {
+	out, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(out)
+}
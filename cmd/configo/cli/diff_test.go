@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"default.yml":      "db:\n  host: localhost\n  port: 5432\n  password: hunter2\nsecret:\n  - db.password\n",
+		"staging.yml":      "db:\n  user: stage\n",
+		"production.yml":   "db:\n  port: 5433\n  password: correcthorse\n  pool: 10\n",
+		"production-2.yml": "db:\n  host: db2.internal\n",
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := Run(
+		[]string{"diff", "--dir", dir, "--hostname", "web9", "--left", "deployment=staging", "--right", "deployment=production,instance=2"},
+		&stdout, &stderr,
+	)
+
+	assert.Equal(t, 1, code, stderr.String())
+	assert.Equal(
+		t,
+		"--- deployment=staging\n"+
+			"+++ deployment=production,instance=2\n"+
+			"~ db.host = \"localhost\" -> \"db2.internal\"  (default.yml:2:3 -> production-2.yml:2:3)\n"+
+			"~ db.password = \"******\" -> \"******\"  (default.yml:4:3 -> production.yml:3:3)\n"+
+			"+ db.pool = 10  (production.yml:4:3)\n"+
+			"~ db.port = 5432 -> 5433  (default.yml:3:3 -> production.yml:2:3)\n"+
+			"- db.user = \"stage\"  (staging.yml:2:3)\n",
+		stdout.String(),
+	)
+
+	stdout.Reset()
+	code = Run(
+		[]string{"diff", "--dir", dir, "--hostname", "web9", "--left", "deployment=production", "--right", "deployment=production,hostname=web9"},
+		&stdout, &stderr,
+	)
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Empty(t, stdout.String())
+
+	code = Run([]string{"diff", "--dir", dir, "--left", "deployment=staging", "--right", "region=eu"}, &stdout, &stderr)
+	assert.Equal(t, 2, code)
+
+	code = Run([]string{"diff", "--dir", dir, "--left", "deployment=staging"}, &stdout, &stderr)
+	assert.Equal(t, 2, code)
+}
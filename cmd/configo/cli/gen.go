@@ -0,0 +1,432 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/affanshahid/configo"
+)
+
+// genNode describes a key of the default configurations
+type genNode struct {
+	kind        string // object, string, integer, number, boolean, array or any
+	items       string // kind of the items of arrays
+	format      string // duration or date-time for strings
+	secret      bool
+	description string
+	children    map[string]*genNode
+}
+
+// accessor maps the kinds of leaves to the generated type and getter
+type accessor struct {
+	goType, getter string
+}
+
+// initialisms are written in upper case in generated names
+var initialisms = map[string]bool{
+	"API": true, "DB": true, "DNS": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "SSL": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true,
+	"UI": true, "URI": true, "URL": true, "UUID": true, "XML": true, "YAML": true,
+}
+
+// gen generates typed accessors for the keys of `default.EXT`.
+// A JSON Schema can refine the inferred types, add keys and describe them
+func gen(opts *options, args []string, stdout io.Writer) error {
+	if len(args) != 0 || opts.pkg == "" {
+		return errUsage
+	}
+
+	root, err := readDefaults(opts.dir)
+	if err != nil {
+		return err
+	}
+
+	if opts.schema != "" {
+		in, err := os.ReadFile(opts.schema)
+		if err != nil {
+			return err
+		}
+
+		schema, err := configo.Parse(in, filepath.Ext(opts.schema))
+		if err != nil {
+			return fmt.Errorf("%s: %w", opts.schema, err)
+		}
+
+		applySchema(root, schema)
+	}
+
+	// New and Config are declared by the generated file itself
+	g := &generator{names: map[string]bool{"New": true, "Config": true}}
+	g.object("Config", "the configurations", root, nil)
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by configo gen; DO NOT EDIT.\n\npackage %s\n\n", opts.pkg)
+	if g.usesTime {
+		fmt.Fprintf(&src, "import (\n\t\"time\"\n\n\t\"github.com/affanshahid/configo\"\n)\n\n")
+	} else {
+		fmt.Fprintf(&src, "import \"github.com/affanshahid/configo\"\n\n")
+	}
+	fmt.Fprintf(&src, "// New binds the accessors to the given configurations\n")
+	fmt.Fprintf(&src, "func New(config *configo.Config) Config {\n\treturn Config{config}\n}\n\n")
+	src.Write(g.buf.Bytes())
+
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if opts.out == "" || opts.out == "-" {
+		_, err = stdout.Write(out)
+		return err
+	}
+
+	return os.WriteFile(opts.out, out, 0o644)
+}
+
+// readDefaults reads the `default.EXT` files of the directory, merged in extension order
+func readDefaults(dir string) (*genNode, error) {
+	root := &genNode{kind: "object", children: map[string]*genNode{}}
+	var secrets [][]string
+	found := false
+
+	for _, ext := range configo.Extensions() {
+		name := filepath.Join(dir, "default"+ext)
+
+		in, err := os.ReadFile(name)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := configo.Parse(in, ext)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		if paths, ok := data["secret"].([]interface{}); ok {
+			for _, p := range paths {
+				path, _ := p.(string)
+				if keys, err := configo.SplitPath(path); err == nil {
+					secrets = append(secrets, keys)
+				}
+			}
+			delete(data, "secret")
+		}
+
+		mergeNode(root, infer(data))
+		found = true
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no default file in %s", dir)
+	}
+
+	for _, keys := range secrets {
+		markSecret(root, keys)
+	}
+
+	return root, nil
+}
+
+func infer(value interface{}) *genNode {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		n := &genNode{kind: "object", children: map[string]*genNode{}}
+		for key, val := range v {
+			n.children[key] = infer(val)
+		}
+		return n
+	case []interface{}:
+		n := &genNode{kind: "array", items: "any"}
+		for i, item := range v {
+			kind := infer(item).kind
+			if i == 0 {
+				n.items = kind
+			} else if kind != n.items {
+				n.items = "any"
+			}
+		}
+		return n
+	case string:
+		return &genNode{kind: "string"}
+	case bool:
+		return &genNode{kind: "boolean"}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return &genNode{kind: "integer"}
+	case float32:
+		return infer(float64(v))
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < math.MaxInt32 {
+			return &genNode{kind: "integer"}
+		}
+		return &genNode{kind: "number"}
+	}
+
+	return &genNode{kind: "any"}
+}
+
+// mergeNode merges src into dst the way files override each other
+func mergeNode(dst, src *genNode) {
+	for key, child := range src.children {
+		if existing, found := dst.children[key]; found && existing.kind == "object" && child.kind == "object" {
+			mergeNode(existing, child)
+		} else {
+			dst.children[key] = child
+		}
+	}
+}
+
+func markSecret(n *genNode, keys []string) {
+	if len(keys) == 0 {
+		n.secret = true
+		for _, child := range n.children {
+			markSecret(child, nil)
+		}
+		return
+	}
+
+	if child, found := n.children[keys[0]]; found {
+		markSecret(child, keys[1:])
+	}
+}
+
+// applySchema refines the node with the type, format, description, items and properties keywords
+func applySchema(n *genNode, schema map[string]interface{}) {
+	if kind, ok := schema["type"].(string); ok {
+		if kind == "null" {
+			kind = "any"
+		}
+		if kind != n.kind {
+			n.kind = kind
+			n.children = nil
+		}
+	}
+
+	if f, ok := schema["format"].(string); ok {
+		n.format = f
+	}
+
+	if description, ok := schema["description"].(string); ok {
+		n.description = description
+	}
+
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		if kind, ok := items["type"].(string); ok {
+			n.items = kind
+		}
+	}
+
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if n.kind != "object" {
+		n.kind = "object"
+	}
+	if n.children == nil {
+		n.children = map[string]*genNode{}
+	}
+
+	for key, property := range properties {
+		propertySchema, ok := property.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		child, found := n.children[key]
+		if !found {
+			child = &genNode{kind: "any"}
+			n.children[key] = child
+		}
+
+		applySchema(child, propertySchema)
+	}
+}
+
+type generator struct {
+	buf      bytes.Buffer
+	names    map[string]bool
+	usesTime bool
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// object generates the accessor type of an object and the types of its nested objects
+func (g *generator) object(typeName, description string, n *genNode, keys []string) {
+	g.names[typeName] = true
+
+	g.printf("// %s provides typed access to %s\n", typeName, description)
+	g.printf("type %s struct {\n\tconfig *configo.Config\n}\n\n", typeName)
+
+	type nested struct {
+		typeName string
+		node     *genNode
+		keys     []string
+	}
+	var objects []nested
+
+	methods := map[string]bool{}
+
+	for _, key := range sortedKeys(n.children) {
+		child := n.children[key]
+		childKeys := append(keys[:len(keys):len(keys)], key)
+		path := keyPath(childKeys)
+		method := uniqueName(goName(key), methods)
+
+		if child.kind == "object" && len(child.children) > 0 {
+			childType := g.uniqueType(typeName, method)
+			g.printf("// %s returns the accessors of %s\n", method, path)
+			g.description(child)
+			g.printf("func (c %s) %s() %s {\n\treturn %s{c.config}\n}\n\n", typeName, method, childType, childType)
+			objects = append(objects, nested{childType, child, childKeys})
+			continue
+		}
+
+		a := leafAccessor(child)
+		if strings.HasPrefix(a.goType, "time.") {
+			g.usesTime = true
+		}
+		g.printf("// %s returns the value of %s\n", method, path)
+		g.description(child)
+		g.printf("func (c %s) %s() %s {\n\treturn c.config.%s(%q)\n}\n\n", typeName, method, a.goType, a.getter, path)
+	}
+
+	for _, obj := range objects {
+		g.object(obj.typeName, keyPath(obj.keys), obj.node, obj.keys)
+	}
+}
+
+// description adds the description from the schema to the doc comment
+func (g *generator) description(n *genNode) {
+	if n.description == "" {
+		return
+	}
+
+	g.printf("//\n")
+	for _, line := range strings.Split(strings.TrimSpace(n.description), "\n") {
+		g.printf("// %s\n", line)
+	}
+}
+
+// uniqueType names nested types after their path, i.e `DBPool` for db.pool
+func (g *generator) uniqueType(parent, method string) string {
+	name := method
+	if parent != "Config" {
+		name = parent + method
+	}
+
+	return uniqueName(name, g.names)
+}
+
+func leafAccessor(n *genNode) accessor {
+	if n.secret && n.kind != "object" && n.kind != "array" {
+		return accessor{"configo.Secret", "MustGetSecret"}
+	}
+
+	switch n.kind {
+	case "object":
+		return accessor{"map[string]interface{}", "MustGetStringMap"}
+	case "string":
+		switch n.format {
+		case "duration":
+			return accessor{"time.Duration", "MustGetDuration"}
+		case "date-time":
+			return accessor{"time.Time", "MustGetTime"}
+		}
+		return accessor{"string", "MustGetString"}
+	case "integer":
+		return accessor{"int", "MustGetInt"}
+	case "number":
+		return accessor{"float64", "MustGetFloat64"}
+	case "boolean":
+		return accessor{"bool", "MustGetBool"}
+	case "array":
+		switch n.items {
+		case "string":
+			return accessor{"[]string", "MustGetStringSlice"}
+		case "integer":
+			return accessor{"[]int", "MustGetIntSlice"}
+		}
+	}
+
+	return accessor{"interface{}", "MustGet"}
+}
+
+// keyPath returns the path of the keys
+func keyPath(keys []string) string {
+	return configo.JoinPath(keys...)
+}
+
+// goName converts a key such as `db_host`, `db-host` or `dbHost` into an exported name
+func goName(key string) string {
+	words := strings.FieldsFunc(key, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, word := range words {
+		for _, part := range splitCamel(word) {
+			if upper := strings.ToUpper(part); initialisms[upper] {
+				b.WriteString(upper)
+			} else {
+				b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+			}
+		}
+	}
+
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "X" + name
+	}
+
+	return name
+}
+
+// splitCamel splits `dbHost` into `db` and `Host`
+func splitCamel(word string) []string {
+	var parts []string
+	start := 0
+
+	runes := []rune(word)
+	for i := 1; i < len(runes); i++ {
+		if unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i-1]) {
+			parts = append(parts, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	return append(parts, string(runes[start:]))
+}
+
+func uniqueName(name string, taken map[string]bool) string {
+	unique := name
+	for i := 2; taken[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	taken[unique] = true
+
+	return unique
+}
+
+func sortedKeys(m map[string]*genNode) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
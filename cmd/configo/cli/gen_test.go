@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGen(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"default.yml": `
+db:
+  host: localhost
+  port: 5432
+  password: hunter2
+  pool:
+    max_idle: 4
+    ratio: 0.5
+debug: false
+tags: [a, b]
+timeout: 5s
+my.key: value
+secret:
+  - db.password
+`,
+		"schema.json": `{
+  "properties": {
+    "timeout": {"type": "string", "format": "duration", "description": "Timeout of requests"},
+    "api_url": {"type": "string"}
+  }
+}`,
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"gen", "--dir", dir, "--package", "appconfig", "--schema", filepath.Join(dir, "schema.json")}, &stdout, &stderr)
+	assert.Equal(t, 0, code, stderr.String())
+
+	assert.Equal(t, `// Code generated by configo gen; DO NOT EDIT.
+
+package appconfig
+
+import (
+	"time"
+
+	"github.com/affanshahid/configo"
+)
+
+// New binds the accessors to the given configurations
+func New(config *configo.Config) Config {
+	return Config{config}
+}
+
+// Config provides typed access to the configurations
+type Config struct {
+	config *configo.Config
+}
+
+// APIURL returns the value of api_url
+func (c Config) APIURL() string {
+	return c.config.MustGetString("api_url")
+}
+
+// DB returns the accessors of db
+func (c Config) DB() DB {
+	return DB{c.config}
+}
+
+// Debug returns the value of debug
+func (c Config) Debug() bool {
+	return c.config.MustGetBool("debug")
+}
+
+// MyKey returns the value of "my.key"
+func (c Config) MyKey() string {
+	return c.config.MustGetString("\"my.key\"")
+}
+
+// Tags returns the value of tags
+func (c Config) Tags() []string {
+	return c.config.MustGetStringSlice("tags")
+}
+
+// Timeout returns the value of timeout
+//
+// Timeout of requests
+func (c Config) Timeout() time.Duration {
+	return c.config.MustGetDuration("timeout")
+}
+
+// DB provides typed access to db
+type DB struct {
+	config *configo.Config
+}
+
+// Host returns the value of db.host
+func (c DB) Host() string {
+	return c.config.MustGetString("db.host")
+}
+
+// Password returns the value of db.password
+func (c DB) Password() configo.Secret {
+	return c.config.MustGetSecret("db.password")
+}
+
+// Pool returns the accessors of db.pool
+func (c DB) Pool() DBPool {
+	return DBPool{c.config}
+}
+
+// Port returns the value of db.port
+func (c DB) Port() int {
+	return c.config.MustGetInt("db.port")
+}
+
+// DBPool provides typed access to db.pool
+type DBPool struct {
+	config *configo.Config
+}
+
+// MaxIdle returns the value of db.pool.max_idle
+func (c DBPool) MaxIdle() int {
+	return c.config.MustGetInt("db.pool.max_idle")
+}
+
+// Ratio returns the value of db.pool.ratio
+func (c DBPool) Ratio() float64 {
+	return c.config.MustGetFloat64("db.pool.ratio")
+}
+`, stdout.String())
+
+	code = Run([]string{"gen", "--dir", dir}, &stdout, &stderr)
+	assert.Equal(t, 2, code)
+}
+
+func TestGenCompiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"default.json": `{"db": {"host": "localhost", "port": 5432}, "ratio": 0.5}`,
+		"default.yml": `
+db:
+  password: hunter2
+  replicas: [a, b]
+http:
+  timeout: 5s
+  started: 2024-01-31T12:00:00Z
+"1st key": true
+type: web
+nested:
+  - name: a
+new:
+  a: 1
+config:
+  b: 2
+secret:
+  - db.password
+`,
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"gen", "--dir", dir, "--package", "appconfig"}, &stdout, &stderr)
+	assert.Equal(t, 0, code, stderr.String())
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "config_gen.go", stdout.Bytes(), parser.ParseComments)
+	assert.Nilf(t, err, "err should be nil")
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check("appconfig", fset, []*ast.File{file}, nil)
+	assert.Nilf(t, err, "err should be nil")
+}
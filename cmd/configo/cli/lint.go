@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/affanshahid/configo"
+)
+
+// lint prints the issues found in the configuration directory
+func lint(opts *options, args []string, stdout io.Writer) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+
+	config, err := configo.NewConfig(os.DirFS(opts.dir), append(opts.configOptions(), opts.environment...)...)
+	if err != nil {
+		return err
+	}
+
+	var lintOpts []configo.LintOption
+	if opts.deployments != "" {
+		lintOpts = append(lintOpts, configo.WithLintDeployments(strings.Split(opts.deployments, ",")...))
+	}
+	if opts.hostnames != "" {
+		lintOpts = append(lintOpts, configo.WithLintHostnames(strings.Split(opts.hostnames, ",")...))
+	}
+
+	issues, err := config.Lint(lintOpts...)
+	if err != nil {
+		return err
+	}
+
+	for _, issue := range issues {
+		fmt.Fprintln(stdout, issue)
+	}
+
+	if len(issues) > 0 {
+		return errIssues
+	}
+
+	return nil
+}
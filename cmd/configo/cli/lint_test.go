@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"default.yml":    "database:\n  host: localhost\n",
+		"production.yml": "dtabase:\n  host: prod.internal\n",
+		"staging.yml":    "database:\n  host: staging.internal\n",
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"lint", "--dir", dir, "--hostname", "web1", "--deployments", "staging,production"}, &stdout, &stderr)
+
+	assert.Equal(t, 1, code, stderr.String())
+	assert.Equal(t, "production.yml:1:1: dtabase does not exist in the default configurations (orphan-key)\n", stdout.String())
+
+	stdout.Reset()
+	code = Run([]string{"lint", "--dir", dir, "--hostname", "web1", "--deployment", "production"}, &stdout, &stderr)
+
+	assert.Equal(t, 1, code, stderr.String())
+	assert.Equal(
+		t,
+		"production.yml:1:1: dtabase does not exist in the default configurations (orphan-key)\n"+
+			"staging.yml: matches no template for the known deployments and hostnames (unmatched-file)\n",
+		stdout.String(),
+	)
+
+	stdout.Reset()
+	code = Run([]string{"lint", "--dir", writeFiles(t, map[string]string{"default.yml": "a: 1\n"})}, &stdout, &stderr)
+
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Empty(t, stdout.String())
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/affanshahid/configo"
+)
+
+// migrate rewrites a configuration file to the latest version of the migrations given to `Run`,
+// in place unless an output is given. An output of `-` writes to stdout.
+// Comments are not preserved
+func migrate(opts *options, args []string, stdout io.Writer) error {
+	if len(args) != 1 && len(args) != 2 {
+		return errUsage
+	}
+
+	input, output := args[0], args[0]
+	if len(args) == 2 {
+		output = args[1]
+	}
+
+	info, err := os.Stat(input)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.ReadFile(input)
+	if err != nil {
+		return err
+	}
+
+	config, err := configo.NewConfig(os.DirFS(opts.dir), opts.configOptions()...)
+	if err != nil {
+		return err
+	}
+
+	out, err := config.MigrateFile(in, filepath.Ext(input))
+	if err != nil {
+		return err
+	}
+
+	if output == "-" {
+		_, err = stdout.Write(out)
+		return err
+	}
+
+	return os.WriteFile(output, out, info.Mode().Perm())
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/affanshahid/configo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"local.yml": "db:\n  addr: localhost\n",
+	})
+
+	renameAddr := configo.WithMigration(1, 2, func(data map[string]interface{}) error {
+		db := data["db"].(map[string]interface{})
+		db["host"] = db["addr"]
+		delete(db, "addr")
+		return nil
+	})
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"migrate", "--dir", dir, filepath.Join(dir, "local.yml"), "-"}, &stdout, &stderr, renameAddr)
+	assert.Equal(t, 0, code, stderr.String())
+	assert.Equal(t, "db:\n  host: localhost\nversion: 2\n", stdout.String())
+
+	code = Run([]string{"migrate", "--dir", dir, filepath.Join(dir, "local.yml")}, &stdout, &stderr, renameAddr)
+	assert.Equal(t, 0, code, stderr.String())
+
+	out, err := os.ReadFile(filepath.Join(dir, "local.yml"))
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, "db:\n  host: localhost\nversion: 2\n", string(out))
+
+	stderr.Reset()
+	code = Run([]string{"migrate", "--dir", dir, filepath.Join(dir, "local.yml")}, &stdout, &stderr)
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "configo: no migrations are registered\n", stderr.String())
+}
@@ -0,0 +1,46 @@
+// Command configo prints the configurations a service would load without starting it.
+//
+// Usage:
+//
+//	configo dump    [flags]
+//	configo get     [flags] <path>
+//	configo explain [flags] <path>
+//	configo diff    [flags] --left <spec> --right <spec>
+//	configo lint    [flags]
+//	configo convert [flags] <input> <output>
+//	configo migrate [flags] <input> [output]
+//	configo gen     [flags] --package <name>
+//
+// Flags:
+//
+//	--dir         directory containing the config files (defaults to ".")
+//	--deployment  deployment label (defaults to "dev")
+//	--instance    instance id
+//	--hostname    hostname (defaults to `os.Hostname()`)
+//	--format      output format of dump, get and convert to stdout (defaults to yaml)
+//	--left        environment of the left side of diff, i.e `deployment=staging`
+//	--right       environment of the right side of diff, i.e `deployment=production,instance=2`
+//	--deployments comma separated known deployments for lint (defaults to the deployment)
+//	--hostnames   comma separated known hostnames for lint (defaults to the hostname)
+//	--package     package of the code generated by gen
+//	--schema      JSON Schema refining the types inferred by gen
+//	--out         file written by gen (defaults to stdout)
+//
+// Secrets are always masked.
+//
+// migrate rewrites a file with the migrations of `configo.WithMigration`, which this build has none of.
+// Services build their own command with the migrations using the `cli` package
+//
+// The exit code is 0 on success, 1 on errors and 2 on invalid usage.
+// diff and lint exit with 1 when they find differences or issues and with 2 on errors
+package main
+
+import (
+	"os"
+
+	"github.com/affanshahid/configo/cmd/configo/cli"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
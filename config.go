@@ -1,16 +1,22 @@
 package configo
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/PaesslerAG/jsonpath"
 	"github.com/affanshahid/walkmap"
 	"github.com/imdario/mergo"
 	"github.com/spf13/cast"
@@ -27,25 +33,29 @@ type environment struct {
 // of the einvironment.
 //
 // Files are loading in the following order:
-//	 default.EXT
-//	 default-{instance}.EXT
-//	 {deployment}.EXT
-//	 {deployment}-{instance}.EXT
-//	 {short_hostname}.EXT
-//	 {short_hostname}-{instance}.EXT
-//	 {short_hostname}-{deployment}.EXT
-//	 {short_hostname}-{deployment}-{instance}.EXT
-//	 {full_hostname}.EXT
-//	 {full_hostname}-{instance}.EXT
-//	 {full_hostname}-{deployment}.EXT
-//	 {full_hostname}-{deployment}-{instance}.EXT
-//	 local.EXT
-//	 local-{instance}.EXT
-//	 local-{deployment}.EXT
-//	 local-{deployment}-{instance}.EXT
+//
+//	default.EXT
+//	default-{instance}.EXT
+//	{deployment}.EXT
+//	{deployment}-{instance}.EXT
+//	{short_hostname}.EXT
+//	{short_hostname}-{instance}.EXT
+//	{short_hostname}-{deployment}.EXT
+//	{short_hostname}-{deployment}-{instance}.EXT
+//	{full_hostname}.EXT
+//	{full_hostname}-{instance}.EXT
+//	{full_hostname}-{deployment}.EXT
+//	{full_hostname}-{deployment}-{instance}.EXT
+//	local.EXT
+//	local-{instance}.EXT
+//	local-{deployment}.EXT
+//	local-{deployment}-{instance}.EXT
 //
 // EXT can be: `yaml`, `yml`, `json`, `json5`, `hjson`, `toml`
 //
+// Files sharing a name with different extensions are merged in the order
+// `json`, `json5`, `hjson`, `toml`, `yaml`, `yml`, see `WithDuplicatePolicy`
+//
 // deployment defines your current environment i.e dev, test, prod etc (defaults to "dev")
 //
 // instance can be the node ID in a multi-node deployment (defaults to "")
@@ -54,13 +64,50 @@ type environment struct {
 //
 // fullHostname is the full host name (defaults to `os.Hostname()`)
 //
+// Environment variables and the hostname are read when `Initialize` is called,
+// see `WithEnvLookup` and `WithHostnameResolver`
+//
 // Each file overrides configurations from the file above.
 // There is a special file called `env.EXT` which allows overriding
 // configurations using environment variables
+//
+// Layers which are not files can be added with `WithSource`
 type Config struct {
 	environment
-	dir   fs.FS
-	store map[string]interface{}
+	environmentOpts []environmentOption
+	lookupEnv       func(string) (string, bool)
+	resolveHostname func() (string, error)
+	// hostnameSet is true when `WithHostname` or `WithHostnameFromEnv` is used, the resolver is not called then
+	hostnameSet bool
+	dir         fs.FS
+
+	secretPaths    map[string]struct{}
+	decryptionKeys map[string]func() ([]byte, error)
+	resolvers      map[string]Resolver
+	sources        []sourceEntry
+	timeFormats    map[string]*timeFormat
+
+	migrations         []migration
+	deprecatedKeys     []*deprecatedKey
+	deprecationHandler func(Deprecation)
+	strictDeprecations bool
+
+	duplicatePolicy DuplicatePolicy
+	logger          *slog.Logger
+
+	mu sync.RWMutex
+	snapshot
+}
+
+// snapshot holds the loaded configurations, it is never modified once published
+type snapshot struct {
+	store     map[string]interface{}
+	positions Positions
+	origins   map[string][]Position
+	secrets   map[string]struct{}
+	files     []LoadedFile
+	// index maps the canonical path of every value to the value, see `buildIndex`
+	index map[string]interface{}
 }
 
 // ConfigOption is a functional option to configure a Config instance
@@ -70,15 +117,20 @@ type ConfigOption func(*Config)
 // dir is a FS of the directory containing the config files
 // opts are functional options
 func NewConfig(dir fs.FS, opts ...ConfigOption) (*Config, error) {
-	hostname, err := os.Hostname()
-	if err != nil {
-		return nil, err
+	c := &Config{
+		dir:             dir,
+		environment:     environment{deployment: development},
+		lookupEnv:       os.LookupEnv,
+		resolveHostname: os.Hostname,
+
+		secretPaths:    map[string]struct{}{},
+		decryptionKeys: map[string]func() ([]byte, error){},
+		resolvers:      map[string]Resolver{},
+		timeFormats:    map[string]*timeFormat{},
+
+		logger: slog.New(discardHandler{}),
 	}
 
-	shortHostname := strings.Split(hostname, ".")[0]
-
-	c := &Config{dir: dir, environment: environment{development, "", shortHostname, hostname}}
-
 	for _, opt := range opts {
 		opt(c)
 	}
@@ -86,118 +138,387 @@ func NewConfig(dir fs.FS, opts ...ConfigOption) (*Config, error) {
 	return c, nil
 }
 
+// environmentOption changes the environment when `Initialize` is called,
+// so that environment variables are read through the configured lookup
+type environmentOption func(env *environment, lookupEnv func(string) (string, bool))
+
 // WithDeployment sets the given deployment
 func WithDeployment(deployment string) ConfigOption {
 	return func(c *Config) {
-		c.deployment = deployment
+		c.environmentOpts = append(c.environmentOpts, func(env *environment, _ func(string) (string, bool)) {
+			env.deployment = deployment
+		})
 	}
 }
 
 // WithInstance sets the given instance
 func WithInstance(instance string) ConfigOption {
 	return func(c *Config) {
-		c.instance = instance
+		c.environmentOpts = append(c.environmentOpts, func(env *environment, _ func(string) (string, bool)) {
+			env.instance = instance
+		})
 	}
 }
 
 // WithHostname uses the given string to set shortHostname and fullHostname
 func WithHostname(hostname string) ConfigOption {
 	return func(c *Config) {
-		c.shortHostname = strings.Split(hostname, ".")[0]
-		c.fullHostname = hostname
+		c.hostnameSet = true
+		c.environmentOpts = append(c.environmentOpts, func(env *environment, _ func(string) (string, bool)) {
+			env.setHostname(hostname)
+		})
 	}
 }
 
 // WithDeploymentFromEnv loads the deployment label from the given environment variable
-func WithDeploymentFromEnv(env string) ConfigOption {
-	deployment, exists := os.LookupEnv(env)
-	if exists {
-		return WithDeployment(deployment)
-	} else {
-		return WithDeployment(development)
+// when `Initialize` is called
+func WithDeploymentFromEnv(name string) ConfigOption {
+	return func(c *Config) {
+		c.environmentOpts = append(c.environmentOpts, func(env *environment, lookupEnv func(string) (string, bool)) {
+			deployment, exists := lookupEnv(name)
+			if exists {
+				env.deployment = deployment
+			} else {
+				env.deployment = development
+			}
+		})
 	}
 }
 
 // WithInstanceFromEnv loads the instance id from the given environment variable
-func WithInstanceFromEnv(env string) ConfigOption {
-	return WithInstance(os.Getenv(env))
+// when `Initialize` is called
+func WithInstanceFromEnv(name string) ConfigOption {
+	return func(c *Config) {
+		c.environmentOpts = append(c.environmentOpts, func(env *environment, lookupEnv func(string) (string, bool)) {
+			env.instance, _ = lookupEnv(name)
+		})
+	}
 }
 
 // WithHostnameFromEnv loads the hostname from the given environment variable
-func WithHostnameFromEnv(env string) ConfigOption {
-	return WithHostname(os.Getenv(env))
+// when `Initialize` is called
+func WithHostnameFromEnv(name string) ConfigOption {
+	return func(c *Config) {
+		c.hostnameSet = true
+		c.environmentOpts = append(c.environmentOpts, func(env *environment, lookupEnv func(string) (string, bool)) {
+			hostname, _ := lookupEnv(name)
+			env.setHostname(hostname)
+		})
+	}
+}
+
+// WithEnvLookup sets the function used to read environment variables (defaults to `os.LookupEnv`).
+// It is used by the `*FromEnv` options, the mappings of `env.EXT`, `WithDecryptionKeyFromEnv`
+// and is passed on to sources and resolvers, see `LookupEnv`
+func WithEnvLookup(lookupEnv func(name string) (string, bool)) ConfigOption {
+	return func(c *Config) {
+		c.lookupEnv = lookupEnv
+	}
+}
+
+// WithHostnameResolver sets the function used to read the hostname (defaults to `os.Hostname`).
+// It is called by `Initialize` unless `WithHostname` or `WithHostnameFromEnv` is used
+func WithHostnameResolver(resolve func() (string, error)) ConfigOption {
+	return func(c *Config) {
+		c.resolveHostname = resolve
+	}
+}
+
+type lookupEnvKey struct{}
+
+// LookupEnv reads an environment variable with the lookup configured by `WithEnvLookup`
+// of the Config loading a source or resolving a reference, ctx being the context passed to them.
+// It falls back to `os.LookupEnv` for other contexts
+func LookupEnv(ctx context.Context, name string) (string, bool) {
+	if lookupEnv, ok := ctx.Value(lookupEnvKey{}).(func(string) (string, bool)); ok {
+		return lookupEnv(name)
+	}
+
+	return os.LookupEnv(name)
+}
+
+// withLookupEnv attaches the lookup of the Config to the context passed to sources and resolvers
+func (c *Config) withLookupEnv(ctx context.Context) context.Context {
+	return context.WithValue(ctx, lookupEnvKey{}, c.lookupEnv)
+}
+
+func (env *environment) setHostname(hostname string) {
+	env.shortHostname = strings.Split(hostname, ".")[0]
+	env.fullHostname = hostname
+}
+
+// resolveEnvironment resolves the hostname, unless an option sets it, and applies the environment options in order
+func (c *Config) resolveEnvironment() (environment, error) {
+	env := c.environment
+
+	if !c.hostnameSet {
+		hostname, err := c.resolveHostname()
+		if err != nil {
+			return env, fmt.Errorf("unable to resolve hostname: %w", err)
+		}
+		env.setHostname(hostname)
+	}
+
+	for _, opt := range c.environmentOpts {
+		opt(&env, c.lookupEnv)
+	}
+
+	return env, nil
 }
 
 // Initialize initializes and loads in the configurations
 // This must be called before attempting to get values
+//
+// Initialize can be called again to reload the configurations, see `Reload`
 func (c *Config) Initialize() error {
-	c.store = map[string]interface{}{}
+	return c.InitializeContext(context.Background())
+}
 
-	files, err := fs.ReadDir(c.dir, ".")
+// InitializeContext is the same as `Initialize` except the context
+// is passed on to sources and resolvers
+func (c *Config) InitializeContext(ctx context.Context) error {
+	s, err := c.load(c.withLookupEnv(ctx))
 	if err != nil {
 		return err
 	}
 
-	fileMap := map[string]fs.DirEntry{}
+	c.mu.Lock()
+	c.snapshot = *s
+	c.mu.Unlock()
 
-	for _, file := range files {
-		if file.IsDir() {
-			continue
-		}
+	return nil
+}
+
+// Reload loads the configurations again and replaces the current ones in one step,
+// values read concurrently come either from the previous or from the reloaded configurations.
+// The previous configurations are kept in case of an error
+func (c *Config) Reload() error {
+	return c.InitializeContext(context.Background())
+}
 
-		nameWithoutExt := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
-		fileMap[nameWithoutExt] = file
+// ReloadContext is the same as `Reload` except the context
+// is passed on to sources and resolvers
+func (c *Config) ReloadContext(ctx context.Context) error {
+	return c.InitializeContext(ctx)
+}
+
+func (c *Config) load(ctx context.Context) (*snapshot, error) {
+	s := &snapshot{
+		store:     map[string]interface{}{},
+		positions: Positions{},
+		origins:   map[string][]Position{},
+		secrets:   map[string]struct{}{},
 	}
 
-	for _, tmpl := range orderedTemplates {
-		filename := getExpectedBasename(tmpl, c.environment)
-		if filename == "" {
-			continue
-		}
+	for path := range c.secretPaths {
+		s.secrets[path] = struct{}{}
+	}
 
-		entry, found := fileMap[filename]
-		if !found {
-			continue
-		}
+	err := c.validateSources()
+	if err != nil {
+		return nil, err
+	}
 
-		data, err := c.readFile(entry.Name())
-		if err != nil {
-			return err
+	err = c.validateMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.validateDeprecations()
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.mergeSources(ctx, s, BeforeFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	fileMap, err := c.readDir()
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := c.resolveEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tmpl := range orderedTemplates {
+		filename := getExpectedBasename(tmpl, env)
+
+		entries, found := fileMap[filename]
+		if !found || filename == "" {
+			c.logger.Debug("no file for template", "template", tmpl, "basename", filename)
+		} else {
+			err := c.checkDuplicates(filename, entries)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, entry := range entries {
+				data, positions, file, err := c.readFile(entry.Name())
+				if err != nil {
+					return nil, err
+				}
+
+				s.readSecretDirective(data, positions)
+
+				err = c.migrateFile(entry.Name(), data, positions)
+				if err != nil {
+					return nil, err
+				}
+
+				err = c.migrateDeprecated(data, positions, c.reportDeprecation)
+				if err != nil {
+					return nil, err
+				}
+
+				err = s.merge(data, positions)
+				if err != nil {
+					return nil, err
+				}
+
+				file.Template = tmpl
+				s.files = append(s.files, file)
+				c.logger.Info("loaded file", "file", file.Name, "template", tmpl)
+			}
 		}
 
-		err = mergo.Merge(&c.store, data, mergo.WithOverride)
+		err = c.mergeSources(ctx, s, AfterTemplate(tmpl))
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	if envFile, found := fileMap[envFileName]; found {
-		data, err := c.readFile(envFile.Name())
+	err = c.mergeSources(ctx, s, AfterFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	if entries, found := fileMap[envFileName]; found {
+		err := c.checkDuplicates(envFileName, entries)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		err = c.loadOverrides(data)
-		if err != nil {
-			return err
+		for _, entry := range entries {
+			data, positions, file, err := c.readFile(entry.Name())
+			if err != nil {
+				return nil, err
+			}
+
+			err = c.migrateDeprecated(data, positions, c.reportDeprecation)
+			if err != nil {
+				return nil, err
+			}
+
+			err = s.loadOverrides(data, positions, c.lookupEnv, c.logger)
+			if err != nil {
+				return nil, err
+			}
+
+			file.Template = envFileName
+			s.files = append(s.files, file)
+			c.logger.Info("loaded file", "file", file.Name, "template", envFileName)
 		}
 	}
 
+	c.logDiagnostics(s, fileMap, env)
+
+	err = c.mergeSources(ctx, s, AfterEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.decryptValues(s)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.resolveValues(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	s.index = buildIndex(s.store)
+
+	return s, nil
+}
+
+func (s *snapshot) merge(data map[string]interface{}, positions Positions) error {
+	err := mergo.Merge(&s.store, data, mergo.WithOverride)
+	if err != nil {
+		return err
+	}
+
+	for path, pos := range positions {
+		s.setPosition(path, pos)
+	}
+
 	return nil
 }
 
-func (c *Config) readFile(name string) (map[string]interface{}, error) {
+// setPosition records the position as the latest origin of the path
+func (s *snapshot) setPosition(path string, pos Position) {
+	s.positions[path] = pos
+	s.origins[path] = append(s.origins[path], pos)
+}
+
+// readFile parses the file, the returned LoadedFile has no template
+func (c *Config) readFile(name string) (map[string]interface{}, Positions, LoadedFile, error) {
 	in, err := fs.ReadFile(c.dir, name)
 	if err != nil {
-		return nil, err
+		return nil, nil, LoadedFile{}, err
 	}
 
 	ext := strings.ToLower(filepath.Ext(name))
 	provider := defaultProviders[ext]
-	return provider.Parse(in)
+
+	data, positions, err := parseWith(provider, in)
+	if err != nil {
+		return nil, nil, LoadedFile{}, withFile(err, name)
+	}
+
+	for path, pos := range positions {
+		pos.File = name
+		positions[path] = pos
+	}
+
+	hash := sha256.Sum256(in)
+	file := LoadedFile{
+		Name:   name,
+		Format: formatNames[ext],
+		Size:   int64(len(in)),
+		Hash:   hex.EncodeToString(hash[:]),
+	}
+
+	return data, positions, file, nil
+}
+
+// parseWith parses the input with the given provider, collecting
+// key positions if the provider supports it
+func parseWith(provider Provider, in []byte) (map[string]interface{}, Positions, error) {
+	if pp, ok := provider.(PositionProvider); ok {
+		return pp.ParsePositions(in)
+	}
+
+	data, err := provider.Parse(in)
+	return data, Positions{}, err
+}
+
+// withFile converts the error into a ParseError for the given file
+func withFile(err error, name string) error {
+	var perr *ParseError
+	if !errors.As(err, &perr) {
+		perr = &ParseError{Err: err}
+	}
+	perr.File = name
+
+	return perr
 }
 
-func (c *Config) loadOverrides(data map[string]interface{}) error {
+func (s *snapshot) loadOverrides(data map[string]interface{}, positions Positions, lookupEnv func(string) (string, bool), logger *slog.Logger) error {
 	var err error
 	walkmap.Walk(data, func(keyPath []interface{}, value interface{}, kind reflect.Kind) {
 		if err != nil {
@@ -210,184 +531,442 @@ func (c *Config) loadOverrides(data map[string]interface{}) error {
 			strPath[i] = p.(string)
 		}
 
+		path := JoinPath(strPath...)
+		pos := positions[path]
+
 		envName, ok := value.(string)
 		if !ok {
-			err = fmt.Errorf("invalid environment variable %s", envName)
+			err = fmt.Errorf("%s: invalid environment variable %v", pos, value)
 			return
 		}
 
-		if envValue, found := os.LookupEnv(envName); found {
-			c.set(strPath, envValue)
+		if envValue, found := lookupEnv(envName); found {
+			_, err = setIn(s.store, strPath, envValue)
+			if err != nil {
+				err = fmt.Errorf("%s: unable to set %s from %s: %w", pos, path, envName, err)
+				return
+			}
+
+			s.setPosition(path, pos)
+			logger.Info("applied environment variable", "variable", envName, "path", path)
+		} else {
+			logger.Debug("environment variable not set", "variable", envName, "path", path)
 		}
 	})
 
 	return err
 }
 
-func (c *Config) set(paths []string, val interface{}) {
-	v := reflect.ValueOf(c.store)
-	for i := 0; i < len(paths)-1; i++ {
-		s := paths[i]
-		if v.Kind() == reflect.Interface {
-			v = v.Elem()
+// deepCopy copies nested maps and slices of the value
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			out[key] = deepCopy(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = deepCopy(val)
+		}
+		return out
+	}
+
+	return value
+}
+
+// transformValues replaces every leaf value in the map with the result of fn.
+// Leaves are visited in a stable order and all errors are returned together
+func transformValues(data map[string]interface{}, fn func(path string, value interface{}) (interface{}, error)) error {
+	var errs []error
+
+	var walk func(value interface{}, path string) interface{}
+	walk = func(value interface{}, path string) interface{} {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				v[key] = walk(v[key], joinKeyPath(path, key))
+			}
+			return v
+		case []interface{}:
+			for i := range v {
+				v[i] = walk(v[i], joinKeyPath(path, strconv.Itoa(i)))
+			}
+			return v
 		}
-		if i, err := strconv.Atoi(s); err == nil {
-			v = v.Index(i)
+
+		out, err := fn(path, value)
+		if err != nil {
+			errs = append(errs, err)
+			return value
 		}
-		v = v.MapIndex(reflect.ValueOf(s))
+		return out
+	}
+
+	walk(data, "")
+	return errors.Join(errs...)
+}
+
+// Position returns the position of the file which defined the value at the given
+// path. For values overridden from environment variables this is the
+// position of the mapping in the `env.EXT` file
+func (c *Config) Position(path string) (Position, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	pos, found := c.positions[canonicalPath(path)]
+	return pos, found
+}
+
+// Origins returns the positions of every layer which set the value at the given
+// path, from the lowest to the highest precedence. The last one is the
+// position returned by `Position`
+func (c *Config) Origins(path string) []Position {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]Position(nil), c.origins[canonicalPath(path)]...)
+}
+
+// Set replaces the value at the given path, creating missing maps along the way.
+// Values read concurrently are either the previous or the new ones
+func (c *Config) Set(path string, value interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.store == nil {
+		return ErrNotInitialized
+	}
+
+	keys, err := SplitPath(path)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("unable to set %s: the root cannot be replaced", path)
+	}
+
+	store := deepCopy(c.store).(map[string]interface{})
+
+	updated, err := setIn(store, keys, deepCopy(value))
+	if err != nil {
+		return fmt.Errorf("unable to set %s: %w", path, err)
 	}
 
-	if v.Kind() == reflect.Interface {
-		v = v.Elem()
+	c.store = updated.(map[string]interface{})
+	c.index = buildIndex(c.store)
+	return nil
+}
+
+// Unset removes the value at the given path, missing and invalid paths are ignored
+func (c *Config) Unset(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keys, err := SplitPath(path)
+	if c.store == nil || err != nil || len(keys) == 0 {
+		return
 	}
 
-	v.SetMapIndex(reflect.ValueOf(paths[len(paths)-1]), reflect.ValueOf(val))
+	store := deepCopy(c.store).(map[string]interface{})
+	unsetIn(store, keys)
+
+	c.store = store
+	c.index = buildIndex(store)
+}
+
+func setIn(parent interface{}, keys []string, value interface{}) (interface{}, error) {
+	if len(keys) == 0 {
+		return value, nil
+	}
+
+	key, rest := keys[0], keys[1:]
+
+	if list, ok := parent.([]interface{}); ok {
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(list) {
+			return nil, fmt.Errorf("invalid index %s", key)
+		}
+
+		item, err := setIn(list[i], rest, value)
+		if err != nil {
+			return nil, err
+		}
+
+		list[i] = item
+		return list, nil
+	}
+
+	m, ok := parent.(map[string]interface{})
+	if !ok {
+		m = map[string]interface{}{}
+	}
+
+	child, err := setIn(m[key], rest, value)
+	if err != nil {
+		return nil, err
+	}
+
+	m[key] = child
+	return m, nil
+}
+
+// getIn returns the value at the keys and whether it exists
+func getIn(value interface{}, keys []string) (interface{}, bool) {
+	for _, key := range keys {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			child, found := v[key]
+			if !found {
+				return nil, false
+			}
+			value = child
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+func unsetIn(parent interface{}, keys []string) {
+	key, rest := keys[0], keys[1:]
+
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		if len(rest) == 0 {
+			delete(p, key)
+			return
+		}
+
+		unsetIn(p[key], rest)
+	case []interface{}:
+		if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(p) && len(rest) > 0 {
+			unsetIn(p[i], rest)
+		}
+	}
+}
+
+// lookup returns the value at the given path, maps and slices are copied
+// so callers cannot modify the loaded configurations
+func (c *Config) lookup(path string) (interface{}, error) {
+	p, err := compilePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.store == nil {
+		return nil, ErrNotInitialized
+	}
+
+	value, found := c.index[p.canonical]
+	if !found {
+		return nil, fmt.Errorf("unknown key %s", path)
+	}
+
+	return deepCopy(value), nil
 }
 
 // Get returns the value at the given path as an interface
 func (c *Config) Get(path string) (interface{}, error) {
-	return jsonpath.Get(path, c.store)
+	return c.lookup(path)
 }
 
 // GetString returns the value at the given path as a string
 func (c *Config) GetString(path string) (string, error) {
-	out, err := jsonpath.Get(path, c.store)
+	out, err := c.lookup(path)
 	if err != nil {
 		return "", err
 	}
 
-	return cast.ToStringE(out)
+	v, err := cast.ToStringE(out)
+	return v, c.redactError(path, err)
 }
 
 // GetBool returns the value at the given path as a boolean
 func (c *Config) GetBool(path string) (bool, error) {
-	out, err := jsonpath.Get(path, c.store)
+	out, err := c.lookup(path)
 	if err != nil {
 		return false, err
 	}
 
-	return cast.ToBoolE(out)
+	v, err := cast.ToBoolE(out)
+	return v, c.redactError(path, err)
 }
 
 // GetInt returns the value at the given path as a int
 func (c *Config) GetInt(path string) (int, error) {
-	out, err := jsonpath.Get(path, c.store)
+	out, err := c.lookup(path)
 	if err != nil {
 		return 0, err
 	}
 
-	return cast.ToIntE(out)
+	v, err := cast.ToIntE(out)
+	return v, c.redactError(path, err)
 }
 
 // GetInt32 returns the value at the given path as a int32
 func (c *Config) GetInt32(path string) (int32, error) {
-	out, err := jsonpath.Get(path, c.store)
+	out, err := c.lookup(path)
 	if err != nil {
 		return 0, err
 	}
 
-	return cast.ToInt32E(out)
+	v, err := cast.ToInt32E(out)
+	return v, c.redactError(path, err)
 }
 
 // GetInt64 returns the value at the given path as a int64
 func (c *Config) GetInt64(path string) (int64, error) {
-	out, err := jsonpath.Get(path, c.store)
+	out, err := c.lookup(path)
 	if err != nil {
 		return 0, err
 	}
 
-	return cast.ToInt64E(out)
+	v, err := cast.ToInt64E(out)
+	return v, c.redactError(path, err)
 }
 
 // GetUint returns the value at the given path as a uint
 func (c *Config) GetUint(path string) (uint, error) {
-	out, err := jsonpath.Get(path, c.store)
+	out, err := c.lookup(path)
 	if err != nil {
 		return 0, err
 	}
 
-	return cast.ToUintE(out)
+	v, err := cast.ToUintE(out)
+	return v, c.redactError(path, err)
 }
 
 // GetUint32 returns the value at the given path as a uint32
 func (c *Config) GetUint32(path string) (uint32, error) {
-	out, err := jsonpath.Get(path, c.store)
+	out, err := c.lookup(path)
 	if err != nil {
 		return 0, err
 	}
 
-	return cast.ToUint32E(out)
+	v, err := cast.ToUint32E(out)
+	return v, c.redactError(path, err)
 }
 
 // GetUint64 returns the value at the given path as a uint64
 func (c *Config) GetUint64(path string) (uint64, error) {
-	out, err := jsonpath.Get(path, c.store)
+	out, err := c.lookup(path)
 	if err != nil {
 		return 0, err
 	}
 
-	return cast.ToUint64E(out)
+	v, err := cast.ToUint64E(out)
+	return v, c.redactError(path, err)
 }
 
 // GetFloat64 returns the value at the given path as a float64
 func (c *Config) GetFloat64(path string) (float64, error) {
-	out, err := jsonpath.Get(path, c.store)
+	out, err := c.lookup(path)
 	if err != nil {
 		return 0, err
 	}
 
-	return cast.ToFloat64E(out)
+	v, err := cast.ToFloat64E(out)
+	return v, c.redactError(path, err)
 }
 
-// GetTime returns the value at the given path as time
+// GetTime returns the value at the given path as time, see `WithTimeLayouts` and `WithTimeLocation`
 func (c *Config) GetTime(path string) (time.Time, error) {
-	out, err := jsonpath.Get(path, c.store)
+	out, err := c.lookup(path)
 	if err != nil {
 		return time.Time{}, err
 	}
 
-	return cast.ToTimeE(out)
+	v, err := parseTime(out, c.timeFormat(path))
+	if err != nil {
+		return time.Time{}, c.redactError(path, conversionError(path, out, "a time", err))
+	}
+
+	return v, nil
 }
 
-// GetDuration returns the value at the given path as a duration
+// GetDuration returns the value at the given path as a duration.
+// Strings are Go durations which may also use `d` for days and `w` for weeks, i.e `1d12h`,
+// or ISO 8601 durations such as `P1DT12H`. Numbers are multiples of the unit set by `WithDurationUnit`
 func (c *Config) GetDuration(path string) (time.Duration, error) {
-	out, err := jsonpath.Get(path, c.store)
+	out, err := c.lookup(path)
 	if err != nil {
 		return time.Duration(0), err
 	}
 
-	return cast.ToDurationE(out)
+	v, err := parseDuration(out, c.timeFormat(path).durationUnit)
+	if err != nil {
+		return time.Duration(0), c.redactError(path, conversionError(path, out, "a duration", err))
+	}
+
+	return v, nil
 }
 
 // GetIntSlice returns the value at the given path as a slice of int values
 func (c *Config) GetIntSlice(path string) ([]int, error) {
-	out, err := jsonpath.Get(path, c.store)
+	out, err := c.lookup(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return cast.ToIntSliceE(out)
+	v, err := cast.ToIntSliceE(out)
+	return v, c.redactError(path, err)
 }
 
 // GetStringSlice returns the value at the given path as a slice of string values
 func (c *Config) GetStringSlice(path string) ([]string, error) {
-	out, err := jsonpath.Get(path, c.store)
+	out, err := c.lookup(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return cast.ToStringSliceE(out)
+	v, err := cast.ToStringSliceE(out)
+	return v, c.redactError(path, err)
+}
+
+// GetSecret returns the value at the given path as a Secret
+func (c *Config) GetSecret(path string) (Secret, error) {
+	out, err := c.lookup(path)
+	if err != nil {
+		return Secret{}, err
+	}
+
+	v, err := cast.ToStringE(out)
+	if err != nil {
+		return Secret{}, fmt.Errorf("unable to convert secret value at %s", path)
+	}
+
+	return NewSecret(v), nil
 }
 
 // GetStringMap returns the value at the given path as a map with string keys
 // and values as interfaces
 func (c *Config) GetStringMap(path string) (map[string]interface{}, error) {
-	out, err := jsonpath.Get(path, c.store)
+	out, err := c.lookup(path)
 	if err != nil {
 		return nil, err
 	}
 
-	return cast.ToStringMapE(out)
+	v, err := cast.ToStringMapE(out)
+	return v, c.redactError(path, err)
 }
 
 // MustGet is the same as `Get` except it panics in case of an error
@@ -516,6 +1095,15 @@ func (c *Config) MustGetStringSlice(path string) []string {
 	return v
 }
 
+// MustGetSecret is the same as `GetSecret` except it panics in case of an error
+func (c *Config) MustGetSecret(path string) Secret {
+	v, err := c.GetSecret(path)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
 // MustGetStringMap is the same as `GetStringMap` except it panics in case of an error
 func (c *Config) MustGetStringMap(path string) map[string]interface{} {
 	v, err := c.GetStringMap(path)
@@ -1,6 +1,7 @@
 package configo_test
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -194,6 +195,165 @@ func TestParseError(t *testing.T) {
 
 	err = config.Initialize()
 	assert.NotNil(t, err)
+
+	var perr *configo.ParseError
+	assert.True(t, errors.As(err, &perr))
+	assert.Equal(t, "default.yml", perr.File)
+	assert.Equal(t, 3, perr.Line)
+}
+
+func TestParseErrorPositions(t *testing.T) {
+	cases := []struct {
+		file         string
+		data         string
+		line, column int
+	}{
+		{"default.yml", "p1: foo\np2: bar\n  p3: baz\n", 3, 3},
+		{"default.json", "{\n  \"p1\": \"foo\",\n  \"p2\" \"bar\"\n}", 3, 8},
+		{"default.json5", "{\n  p1: 'foo',\n  p2 'bar'\n}", 3, 6},
+		{"default.hjson", "{\n  p1: foo\n  p2\n}", 4, 1},
+		{"default.toml", "p1 = \"foo\"\np2 = = \"bar\"\n", 2, 6},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.file, func(t *testing.T) {
+			dir := fstest.MapFS{tc.file: {Data: []byte(tc.data)}}
+
+			config, err := configo.NewConfig(dir)
+			assert.Nilf(t, err, "err should be nil")
+
+			err = config.Initialize()
+			var perr *configo.ParseError
+			assert.True(t, errors.As(err, &perr))
+			assert.Equal(t, tc.file, perr.File)
+			assert.Equal(t, tc.line, perr.Line)
+			assert.Equal(t, tc.column, perr.Column)
+		})
+	}
+}
+
+func TestEmptyYamlDocuments(t *testing.T) {
+	for _, data := range []string{"", "---\n", "~\n", "null\n", "--- # nothing yet\n"} {
+		dir := fstest.MapFS{
+			"default.yml": {Data: []byte("p1: foo\n")},
+			"local.yml":   {Data: []byte(data)},
+		}
+
+		config, err := configo.NewConfig(dir)
+		assert.Nilf(t, err, "err should be nil")
+
+		err = config.Initialize()
+		assert.Nilf(t, err, "err should be nil for %q", data)
+		assert.Equal(t, "foo", config.MustGetString("p1"))
+	}
+
+	dir := fstest.MapFS{"default.yml": {Data: []byte("---\n- a\n")}}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.EqualError(t, err, "default.yml:2:1: expected a mapping at the root of the document")
+}
+
+func TestPosition(t *testing.T) {
+	cases := []struct {
+		file string
+		data string
+	}{
+		{"default.yml", "root:\n  p1: foo\n  list:\n    - a\n    - p2: bar\n"},
+		{"default.json", "{\"root\": {\n  \"p1\": \"foo\",\n  \"list\": [\n    \"a\",\n    {\"p2\": \"bar\"}]}}"},
+		{"default.json5", "{root: {\n  p1: 'foo',\n  list: [\n    'a',\n    {p2: 'bar'}]}}"},
+		{"default.hjson", "root: {\n  p1: foo\n  list: [\n    a\n    {\n      p2: bar\n    }\n  ]\n}"},
+		{"default.toml", "[root]\n  p1 = \"foo\"\n  list = [\n    \"a\",\n  ]\n[[root.list2]]\n    p2 = \"bar\"\n"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.file, func(t *testing.T) {
+			dir := fstest.MapFS{tc.file: {Data: []byte(tc.data)}}
+
+			config, err := configo.NewConfig(dir)
+			assert.Nilf(t, err, "err should be nil")
+
+			err = config.Initialize()
+			assert.Nilf(t, err, "err should be nil")
+
+			pos, found := config.Position("root.p1")
+			assert.True(t, found)
+			assert.Equal(t, configo.Position{File: tc.file, Line: 2, Column: 3}, pos)
+
+			if tc.file == "default.toml" {
+				pos, found = config.Position("root.list2.0.p2")
+			} else {
+				pos, found = config.Position("root.list.1.p2")
+			}
+			assert.True(t, found)
+			assert.Equal(t, tc.file, pos.File)
+			assert.Greater(t, pos.Line, 4)
+		})
+	}
+}
+
+func TestPositionOverride(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                root:
+                    prop1: foo
+                    prop2: bar
+            `),
+		},
+		"production.yml": {
+			Data: []byte(`
+                root:
+                    prop2: baz
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir, configo.WithDeployment("production"))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	pos, _ := config.Position("root.prop1")
+	assert.Equal(t, "default.yml:3:21", pos.String())
+
+	pos, _ = config.Position("root.prop2")
+	assert.Equal(t, "production.yml:3:21", pos.String())
+}
+
+func TestOrigins(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                root:
+                    prop1: foo
+                    prop2: bar
+            `),
+		},
+		"production.yml": {
+			Data: []byte(`
+                root:
+                    prop2: baz
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir, configo.WithDeployment("production"))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, []configo.Position{{File: "default.yml", Line: 3, Column: 21}}, config.Origins("root.prop1"))
+	assert.Equal(
+		t,
+		[]configo.Position{{File: "default.yml", Line: 4, Column: 21}, {File: "production.yml", Line: 3, Column: 21}},
+		config.Origins("root.prop2"),
+	)
+	assert.Empty(t, config.Origins("root.prop3"))
 }
 
 func TestWithDeployment(t *testing.T) {
@@ -421,6 +581,70 @@ func TestWithHostnameFromEnv(t *testing.T) {
 	assert.Equal(t, "foobar", config.MustGetString("root.prop3"))
 }
 
+func TestWithHostnameResolver(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                root:
+                    prop1: foo
+                    prop2: bar
+            `),
+		},
+		"service1.yml": {
+			Data: []byte(`
+                root:
+                    prop2: baz
+            `),
+		},
+	}
+
+	resolve := func() (string, error) {
+		return "service1.example.com", nil
+	}
+
+	config, err := configo.NewConfig(dir, configo.WithHostnameResolver(resolve))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, "foo", config.MustGetString("root.prop1"))
+	assert.Equal(t, "baz", config.MustGetString("root.prop2"))
+
+	fail := func() (string, error) {
+		return "", errors.New("no hostname")
+	}
+
+	config, err = configo.NewConfig(dir, configo.WithHostnameResolver(fail))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.ErrorContains(t, err, "no hostname")
+
+	config, err = configo.NewConfig(dir, configo.WithHostnameResolver(fail), configo.WithHostname("service1"))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, "baz", config.MustGetString("root.prop2"))
+
+	lookupEnv := func(name string) (string, bool) {
+		return "service1", name == "HOST"
+	}
+
+	config, err = configo.NewConfig(
+		dir,
+		configo.WithHostnameResolver(fail),
+		configo.WithEnvLookup(lookupEnv),
+		configo.WithHostnameFromEnv("HOST"),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, "baz", config.MustGetString("root.prop2"))
+}
+
 func TestFileLoadingOrder(t *testing.T) {
 	dir := fstest.MapFS{
 		"default.yml": {
@@ -681,6 +905,173 @@ func TestEnvOverride(t *testing.T) {
 	assert.Equal(t, "bar", val)
 }
 
+func TestWithEnvLookup(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                p1: foo
+                p2: bar
+            `),
+		},
+		"production.yml": {
+			Data: []byte(`
+                p1: baz
+            `),
+		},
+		"env.yml": {
+			Data: []byte(`
+                p2: LOOKUP_ENV
+            `),
+		},
+	}
+
+	env := map[string]string{"LOOKUP_DEP": "production", "LOOKUP_ENV": "qux"}
+	lookup := func(name string) (string, bool) {
+		value, found := env[name]
+		return value, found
+	}
+
+	config, err := configo.NewConfig(dir, configo.WithDeploymentFromEnv("LOOKUP_DEP"), configo.WithEnvLookup(lookup))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, "baz", config.MustGetString("p1"))
+	assert.Equal(t, "qux", config.MustGetString("p2"))
+}
+
+func TestSet(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                root:
+                    prop1: foo
+                    list: [1, 2]
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	before := config.MustGetStringMap("root")
+
+	err = config.Set("root.prop1", "bar")
+	assert.Nilf(t, err, "err should be nil")
+	err = config.Set("root.nested.prop2", 2)
+	assert.Nilf(t, err, "err should be nil")
+	err = config.Set("root.list.1", 3)
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, "bar", config.MustGetString("root.prop1"))
+	assert.Equal(t, 2, config.MustGetInt("root.nested.prop2"))
+	assert.Equal(t, []int{1, 3}, config.MustGetIntSlice("root.list"))
+	assert.Equal(t, "foo", before["prop1"])
+
+	err = config.Set("root.list.5", 3)
+	assert.NotNil(t, err)
+
+	config.Unset("root.nested")
+
+	_, err = config.Get("root.nested.prop2")
+	assert.NotNil(t, err)
+}
+
+func TestLoadedFiles(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte("p1: foo\n"),
+		},
+		"production.json": {
+			Data: []byte(`{"p1": "bar"}`),
+		},
+		"staging.json": {
+			Data: []byte(`{"p1": "baz"}`),
+		},
+		"env.toml": {
+			Data: []byte(`p1 = "P1_ENV"`),
+		},
+	}
+
+	config, err := configo.NewConfig(dir, configo.WithDeployment("production"))
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Empty(t, config.LoadedFiles())
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, []configo.LoadedFile{
+		{
+			Name:     "default.yml",
+			Template: "default",
+			Format:   "yaml",
+			Size:     8,
+			Hash:     "2ff27430482ba29fe20b773fe113a75e3a6285af64a12c0ceab079b981541cfb",
+		},
+		{
+			Name:     "production.json",
+			Template: "{deployment}",
+			Format:   "json",
+			Size:     13,
+			Hash:     "02d73d4cfef156d0c89e0e1047f137b50aae9f4306689e4c7a2bec2b7267d4ba",
+		},
+		{
+			Name:     "env.toml",
+			Template: "env",
+			Format:   "toml",
+			Size:     13,
+			Hash:     "0fb6fa5d67ed6fdb2cff213d02e24946b2932e82c0e91a27d6b3a84ad54804b7",
+		},
+	}, config.LoadedFiles())
+}
+
+func TestDuplicateBasenames(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                p1: yml
+                p3: yml
+            `),
+		},
+		"default.json": {
+			Data: []byte(`{"p1": "json", "p2": "json", "p3": "json"}`),
+		},
+		"default.toml": {
+			Data: []byte(`p2 = "toml"`),
+		},
+		"default.txt": {
+			Data: []byte(`not a config file`),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, "yml", config.MustGetString("p1"))
+	assert.Equal(t, "toml", config.MustGetString("p2"))
+	assert.Equal(t, "yml", config.MustGetString("p3"))
+
+	var names []string
+	for _, file := range config.LoadedFiles() {
+		names = append(names, file.Name)
+	}
+	assert.Equal(t, []string{"default.json", "default.toml", "default.yml"}, names)
+
+	config, err = configo.NewConfig(dir, configo.WithDuplicatePolicy(configo.DuplicateError))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.EqualError(t, err, "multiple files named default: default.json, default.toml, default.yml")
+}
+
 func TestGet(t *testing.T) {
 	dir := fstest.MapFS{
 		"default.yml": {
@@ -1,174 +1,345 @@
 package configo
 
 import (
+	"errors"
 	"io/fs"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"sync/atomic"
 	"time"
 )
 
-var globalConfig *Config
+// ErrNotInitialized is returned when values are read before the configurations are initialized
+var ErrNotInitialized = errors.New("configo: configuration is not initialized")
 
-// Initialize loads the global configuration
-func Initialize(dir fs.FS, opts ...ConfigOption) (err error) {
-	globalConfig, err = NewConfig(dir, opts...)
+var globalConfig atomic.Pointer[Config]
+
+// Initialize loads the global configuration.
+// The global configuration is only replaced if loading succeeds
+func Initialize(dir fs.FS, opts ...ConfigOption) error {
+	config, err := NewConfig(dir, opts...)
 	if err != nil {
 		return err
 	}
 
-	err = globalConfig.Initialize()
+	err = config.Initialize()
 	if err != nil {
 		return err
 	}
 
+	globalConfig.Store(config)
+
 	return nil
 }
 
+// Global returns the global configuration, it is nil until `Initialize` or `SetGlobal` is called
+func Global() *Config {
+	return globalConfig.Load()
+}
+
+// SetGlobal replaces the global configuration used by the package-level functions,
+// passing nil resets it. It is safe to call concurrently with the package-level functions
+func SetGlobal(config *Config) {
+	globalConfig.Store(config)
+}
+
+// fromGlobal calls the getter on the global configuration or returns ErrNotInitialized
+func fromGlobal[T interface{}](get func(*Config, string) (T, error), path string) (T, error) {
+	config := Global()
+	if config == nil {
+		var zero T
+		return zero, ErrNotInitialized
+	}
+
+	return get(config, path)
+}
+
+// mustGlobal returns the global configuration and panics with ErrNotInitialized if there is none
+func mustGlobal() *Config {
+	config := Global()
+	if config == nil {
+		panic(ErrNotInitialized)
+	}
+
+	return config
+}
+
 // Get returns the value at the given path as an interface from the globalConfig
 func Get(path string) (interface{}, error) {
-	return globalConfig.Get(path)
+	return fromGlobal((*Config).Get, path)
 }
 
 // GetString returns the value at the given path as a string from the globalConfig
 func GetString(path string) (string, error) {
-	return globalConfig.GetString(path)
+	return fromGlobal((*Config).GetString, path)
 }
 
 // GetBool returns the value at the given path as a boolean from the globalConfig
 func GetBool(path string) (bool, error) {
-	return globalConfig.GetBool(path)
+	return fromGlobal((*Config).GetBool, path)
 }
 
 // GetInt returns the value at the given path as a int from the globalConfig
 func GetInt(path string) (int, error) {
-	return globalConfig.GetInt(path)
+	return fromGlobal((*Config).GetInt, path)
 }
 
 // GetInt32 returns the value at the given path as a int32 from the globalConfig
 func GetInt32(path string) (int32, error) {
-	return globalConfig.GetInt32(path)
+	return fromGlobal((*Config).GetInt32, path)
 }
 
 // GetInt64 returns the value at the given path as a int64 from the globalConfig
 func GetInt64(path string) (int64, error) {
-	return globalConfig.GetInt64(path)
+	return fromGlobal((*Config).GetInt64, path)
 }
 
 // GetUint returns the value at the given path as a uint from the globalConfig
 func GetUint(path string) (uint, error) {
-	return globalConfig.GetUint(path)
+	return fromGlobal((*Config).GetUint, path)
 }
 
 // GetUint32 returns the value at the given path as a uint32 from the globalConfig
 func GetUint32(path string) (uint32, error) {
-	return globalConfig.GetUint32(path)
+	return fromGlobal((*Config).GetUint32, path)
 }
 
 // GetUint64 returns the value at the given path as a uint64 from the globalConfig
 func GetUint64(path string) (uint64, error) {
-	return globalConfig.GetUint64(path)
+	return fromGlobal((*Config).GetUint64, path)
 }
 
 // GetFloat64 returns the value at the given path as a float64 from the globalConfig
 func GetFloat64(path string) (float64, error) {
-	return globalConfig.GetFloat64(path)
+	return fromGlobal((*Config).GetFloat64, path)
 }
 
 // GetTime returns the value at the given path as time
 func GetTime(path string) (time.Time, error) {
-	return globalConfig.GetTime(path)
+	return fromGlobal((*Config).GetTime, path)
 }
 
 // GetDuration returns the value at the given path as a duration from the globalConfig
 func GetDuration(path string) (time.Duration, error) {
-	return globalConfig.GetDuration(path)
+	return fromGlobal((*Config).GetDuration, path)
 }
 
 // GetIntSlice returns the value at the given path as a slice of int values from the globalConfig
 func GetIntSlice(path string) ([]int, error) {
-	return globalConfig.GetIntSlice(path)
+	return fromGlobal((*Config).GetIntSlice, path)
 }
 
 // GetStringSlice returns the value at the given path as a slice of string values from the globalConfig
 func GetStringSlice(path string) ([]string, error) {
-	return globalConfig.GetStringSlice(path)
+	return fromGlobal((*Config).GetStringSlice, path)
+}
+
+// GetSecret returns the value at the given path as a Secret from the globalConfig
+func GetSecret(path string) (Secret, error) {
+	return fromGlobal((*Config).GetSecret, path)
 }
 
 // GetStringMap returns the value at the given path as a map with string keys from the globalConfig
 // and values as interfaces
 func GetStringMap(path string) (map[string]interface{}, error) {
-	return globalConfig.GetStringMap(path)
+	return fromGlobal((*Config).GetStringMap, path)
+}
+
+// GetByteSize returns the value at the given path as a number of bytes from the globalConfig
+func GetByteSize(path string) (uint64, error) {
+	return fromGlobal((*Config).GetByteSize, path)
+}
+
+// GetURL returns the value at the given path as a URL from the globalConfig
+func GetURL(path string) (*url.URL, error) {
+	return fromGlobal((*Config).GetURL, path)
+}
+
+// GetIP returns the value at the given path as an IP address from the globalConfig
+func GetIP(path string) (net.IP, error) {
+	return fromGlobal((*Config).GetIP, path)
+}
+
+// GetPrefix returns the value at the given path as an IP network in CIDR notation from the globalConfig
+func GetPrefix(path string) (netip.Prefix, error) {
+	return fromGlobal((*Config).GetPrefix, path)
+}
+
+// GetRegexp returns the value at the given path as a regular expression from the globalConfig
+func GetRegexp(path string) (*regexp.Regexp, error) {
+	return fromGlobal((*Config).GetRegexp, path)
+}
+
+// GetLocation returns the value at the given path as a time zone from the globalConfig
+func GetLocation(path string) (*time.Location, error) {
+	return fromGlobal((*Config).GetLocation, path)
+}
+
+// GetFileMode returns the value at the given path as file permissions from the globalConfig
+func GetFileMode(path string) (os.FileMode, error) {
+	return fromGlobal((*Config).GetFileMode, path)
+}
+
+// GetDurationSlice returns the value at the given path as a slice of durations from the globalConfig
+func GetDurationSlice(path string) ([]time.Duration, error) {
+	return fromGlobal((*Config).GetDurationSlice, path)
+}
+
+// Has reports whether the dotted key path exists in the globalConfig
+func Has(path string) bool {
+	config := Global()
+	return config != nil && config.Has(path)
+}
+
+// Keys returns the sorted keys of the map at the given path from the globalConfig
+func Keys(path string) []string {
+	config := Global()
+	if config == nil {
+		return nil
+	}
+
+	return config.Keys(path)
+}
+
+// AllKeys returns the sorted dotted paths of every leaf of the globalConfig
+func AllKeys() []string {
+	config := Global()
+	if config == nil {
+		return nil
+	}
+
+	return config.AllKeys()
+}
+
+// AllSettings returns a deep copy of the globalConfig
+func AllSettings() map[string]interface{} {
+	config := Global()
+	if config == nil {
+		return nil
+	}
+
+	return config.AllSettings()
 }
 
 // MustGet is the same as `Get` except it panics in case of an error
 func MustGet(path string) interface{} {
-	return globalConfig.MustGet(path)
+	return mustGlobal().MustGet(path)
 }
 
 // MustGetString is the same as `GetString` except it panics in case of an error
 func MustGetString(path string) string {
-	return globalConfig.MustGetString(path)
+	return mustGlobal().MustGetString(path)
 }
 
 // MustGetBool is the same as `GetBool` except it panics in case of an error
 func MustGetBool(path string) bool {
-	return globalConfig.MustGetBool(path)
+	return mustGlobal().MustGetBool(path)
 }
 
 // MustGetInt is the same as `GetInt` except it panics in case of an error
 func MustGetInt(path string) int {
-	return globalConfig.MustGetInt(path)
+	return mustGlobal().MustGetInt(path)
 }
 
 // MustGetInt32 is the same as `GetInt32` except it panics in case of an error
 func MustGetInt32(path string) int32 {
-	return globalConfig.MustGetInt32(path)
+	return mustGlobal().MustGetInt32(path)
 }
 
 // MustGetInt64 is the same as `GetInt64` except it panics in case of an error
 func MustGetInt64(path string) int64 {
-	return globalConfig.MustGetInt64(path)
+	return mustGlobal().MustGetInt64(path)
 }
 
 // MustGetUint is the same as `GetUint` except it panics in case of an error
 func MustGetUint(path string) uint {
-	return globalConfig.MustGetUint(path)
+	return mustGlobal().MustGetUint(path)
 }
 
 // MustGetUint32 is the same as `GetUint32` except it panics in case of an error
 func MustGetUint32(path string) uint32 {
-	return globalConfig.MustGetUint32(path)
+	return mustGlobal().MustGetUint32(path)
 }
 
 // MustGetUint64 is the same as `GetUint64` except it panics in case of an error
 func MustGetUint64(path string) uint64 {
-	return globalConfig.MustGetUint64(path)
+	return mustGlobal().MustGetUint64(path)
 }
 
 // MustGetFloat64 is the same as `GetFloat64` except it panics in case of an error
 func MustGetFloat64(path string) float64 {
-	return globalConfig.MustGetFloat64(path)
+	return mustGlobal().MustGetFloat64(path)
 }
 
 // MustGetTime is the same as `GetTime` except it panics in case of an error
 func MustGetTime(path string) time.Time {
-	return globalConfig.MustGetTime(path)
+	return mustGlobal().MustGetTime(path)
 }
 
 // MustGetDuration is the same as `GetDuration` except it panics in case of an error
 func MustGetDuration(path string) time.Duration {
-	return globalConfig.MustGetDuration(path)
+	return mustGlobal().MustGetDuration(path)
 }
 
 // MustGetIntSlice is the same as `GetIntSlice` except it panics in case of an error
 func MustGetIntSlice(path string) []int {
-	return globalConfig.MustGetIntSlice(path)
+	return mustGlobal().MustGetIntSlice(path)
 }
 
 // MustGetStringSlice is the same as `GetStringSlice` except it panics in case of an error
 func MustGetStringSlice(path string) []string {
-	return globalConfig.MustGetStringSlice(path)
+	return mustGlobal().MustGetStringSlice(path)
+}
+
+// MustGetSecret is the same as `GetSecret` except it panics in case of an error
+func MustGetSecret(path string) Secret {
+	return mustGlobal().MustGetSecret(path)
 }
 
 // MustGetStringMap is the same as `GetStringMap` except it panics in case of an error
 func MustGetStringMap(path string) map[string]interface{} {
-	return globalConfig.MustGetStringMap(path)
+	return mustGlobal().MustGetStringMap(path)
+}
+
+// MustGetByteSize is the same as `GetByteSize` except it panics in case of an error
+func MustGetByteSize(path string) uint64 {
+	return mustGlobal().MustGetByteSize(path)
+}
+
+// MustGetURL is the same as `GetURL` except it panics in case of an error
+func MustGetURL(path string) *url.URL {
+	return mustGlobal().MustGetURL(path)
+}
+
+// MustGetIP is the same as `GetIP` except it panics in case of an error
+func MustGetIP(path string) net.IP {
+	return mustGlobal().MustGetIP(path)
+}
+
+// MustGetPrefix is the same as `GetPrefix` except it panics in case of an error
+func MustGetPrefix(path string) netip.Prefix {
+	return mustGlobal().MustGetPrefix(path)
+}
+
+// MustGetRegexp is the same as `GetRegexp` except it panics in case of an error
+func MustGetRegexp(path string) *regexp.Regexp {
+	return mustGlobal().MustGetRegexp(path)
+}
+
+// MustGetLocation is the same as `GetLocation` except it panics in case of an error
+func MustGetLocation(path string) *time.Location {
+	return mustGlobal().MustGetLocation(path)
+}
+
+// MustGetFileMode is the same as `GetFileMode` except it panics in case of an error
+func MustGetFileMode(path string) os.FileMode {
+	return mustGlobal().MustGetFileMode(path)
+}
+
+// MustGetDurationSlice is the same as `GetDurationSlice` except it panics in case of an error
+func MustGetDurationSlice(path string) []time.Duration {
+	return mustGlobal().MustGetDurationSlice(path)
 }
@@ -3,6 +3,7 @@ package configo_test
 import (
 	"fmt"
 	"os"
+	"sync"
 	"testing"
 	"testing/fstest"
 
@@ -78,3 +79,92 @@ func TestGlobalConfigParseError(t *testing.T) {
 	err := configo.Initialize(dir)
 	assert.NotNil(t, err)
 }
+
+func TestGlobalConfigKeptOnError(t *testing.T) {
+	previous := configo.Global()
+	defer configo.SetGlobal(previous)
+
+	err := configo.Initialize(fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                root:
+                  prop1: foo
+            `),
+		},
+	})
+	assert.Nilf(t, err, "err should be nil")
+
+	err = configo.Initialize(fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                in valid
+                ; hhvc: foo
+            `),
+		},
+	})
+	assert.NotNil(t, err)
+
+	assert.Equal(t, "foo", configo.MustGetString("root.prop1"))
+}
+
+func TestGlobalConfigNotInitialized(t *testing.T) {
+	previous := configo.Global()
+	defer configo.SetGlobal(previous)
+
+	configo.SetGlobal(nil)
+
+	_, err := configo.GetString("root.prop1")
+	assert.ErrorIs(t, err, configo.ErrNotInitialized)
+
+	assert.PanicsWithValue(t, configo.ErrNotInitialized, func() {
+		configo.MustGetString("root.prop1")
+	})
+
+	config, err := configo.NewConfig(fstest.MapFS{})
+	assert.Nilf(t, err, "err should be nil")
+
+	configo.SetGlobal(config)
+
+	_, err = configo.GetInt("root.prop1")
+	assert.ErrorIs(t, err, configo.ErrNotInitialized)
+}
+
+func TestSetGlobalConcurrently(t *testing.T) {
+	previous := configo.Global()
+	defer configo.SetGlobal(previous)
+
+	configs := make([]*configo.Config, 2)
+	for i, value := range []string{"foo", "bar"} {
+		config, err := configo.NewConfig(fstest.MapFS{
+			"default.yml": {Data: []byte("prop1: " + value)},
+		})
+		assert.Nilf(t, err, "err should be nil")
+
+		err = config.Initialize()
+		assert.Nilf(t, err, "err should be nil")
+
+		configs[i] = config
+	}
+
+	configo.SetGlobal(configs[0])
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				value, err := configo.GetString("prop1")
+				assert.Nilf(t, err, "err should be nil")
+				assert.Contains(t, []string{"foo", "bar"}, value)
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		configo.SetGlobal(configs[i%2])
+	}
+
+	wg.Wait()
+}
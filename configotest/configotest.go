@@ -0,0 +1,148 @@
+// Package configotest provides helpers for tests using configo.
+//
+// Configurations created with `New` never read the process environment or hostname,
+// so tests using them can run in parallel:
+//
+//	func TestHandler(t *testing.T) {
+//		t.Parallel()
+//
+//		config := configotest.New(t, fstest.MapFS{
+//			"default.yml": {Data: []byte("db:\n  host: localhost\n")},
+//		})
+//		configotest.Override(t, config, "db.host", "db.test")
+//		...
+//	}
+package configotest
+
+import (
+	"io/fs"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/affanshahid/configo"
+)
+
+// Hostname is the hostname of configurations created with `New`
+const Hostname = "localhost"
+
+// New creates and initializes a Config, failing the test on errors.
+// Environment variables are empty and the hostname is `Hostname`, use `WithEnv`
+// and `configo.WithHostname` to change them
+func New(t testing.TB, dir fs.FS, opts ...configo.ConfigOption) *configo.Config {
+	t.Helper()
+
+	defaults := []configo.ConfigOption{WithEnv(nil), configo.WithHostnameResolver(resolveHostname)}
+
+	config, err := configo.NewConfig(dir, append(defaults, opts...)...)
+	if err != nil {
+		t.Fatalf("configotest: %v", err)
+	}
+
+	err = config.Initialize()
+	if err != nil {
+		t.Fatalf("configotest: %v", err)
+	}
+
+	return config
+}
+
+func resolveHostname() (string, error) {
+	return Hostname, nil
+}
+
+// WithEnv makes the configurations see only the given environment variables
+func WithEnv(vars map[string]string) configo.ConfigOption {
+	return configo.WithEnvLookup(func(name string) (string, bool) {
+		value, found := vars[name]
+		return value, found
+	})
+}
+
+// Override sets the value at the given path and restores the previous value when the test finishes,
+// paths which did not exist are removed again
+func Override(t testing.TB, config *configo.Config, path string, value interface{}) {
+	t.Helper()
+
+	existed := config.Has(path)
+	previous, _ := config.Get(path)
+
+	err := config.Set(path, value)
+	if err != nil {
+		t.Fatalf("configotest: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if !existed {
+			config.Unset(path)
+			return
+		}
+
+		err := config.Set(path, previous)
+		if err != nil {
+			t.Errorf("configotest: %v", err)
+		}
+	})
+}
+
+// globalHolder is a test holding the global configuration,
+// its subtests take turns holding it through mu
+type globalHolder struct {
+	name string
+	mu   sync.Mutex
+}
+
+var (
+	globalMu sync.Mutex
+	// globalHolders are the tests currently holding the global configuration by name
+	globalHolders = map[string]*globalHolder{}
+	holdersMu     sync.Mutex
+)
+
+// WithGlobal makes the configuration the global one for the duration of the test.
+// Tests calling WithGlobal run one at a time even when they are parallel, subtests of a test
+// holding the global configuration take turns with each other instead of waiting for their parent.
+// The previous global configuration is restored when the test finishes
+func WithGlobal(t testing.TB, config *configo.Config) {
+	t.Helper()
+
+	holdersMu.Lock()
+	_, holding := globalHolders[t.Name()]
+
+	// the closest ancestor holding the global configuration, if any
+	var parent *globalHolder
+	for name, holder := range globalHolders {
+		if strings.HasPrefix(t.Name(), name+"/") && (parent == nil || len(name) > len(parent.name)) {
+			parent = holder
+		}
+	}
+	holdersMu.Unlock()
+
+	var mu *sync.Mutex
+	if !holding {
+		mu = &globalMu
+		if parent != nil {
+			mu = &parent.mu
+		}
+		mu.Lock()
+
+		holdersMu.Lock()
+		globalHolders[t.Name()] = &globalHolder{name: t.Name()}
+		holdersMu.Unlock()
+	}
+
+	previous := configo.Global()
+	configo.SetGlobal(config)
+
+	t.Cleanup(func() {
+		configo.SetGlobal(previous)
+
+		if mu != nil {
+			holdersMu.Lock()
+			delete(globalHolders, t.Name())
+			holdersMu.Unlock()
+
+			mu.Unlock()
+		}
+	})
+}
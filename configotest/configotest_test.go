@@ -0,0 +1,140 @@
+package configotest_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/affanshahid/configo"
+	"github.com/affanshahid/configo/configotest"
+	"github.com/stretchr/testify/assert"
+)
+
+var dir = fstest.MapFS{
+	"default.yml": {
+		Data: []byte(`
+            db:
+                host: localhost
+                port: 5432
+        `),
+	},
+	"production.yml": {
+		Data: []byte(`
+            db:
+                host: prod.internal
+        `),
+	},
+	"localhost.yml": {
+		Data: []byte(`
+            db:
+                port: 5433
+        `),
+	},
+	"env.yml": {
+		Data: []byte(`
+            db:
+                host: DB_HOST
+        `),
+	},
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	config := configotest.New(t, dir)
+	assert.Equal(t, "localhost", config.MustGetString("db.host"))
+	assert.Equal(t, 5433, config.MustGetInt("db.port"))
+}
+
+func TestWithEnv(t *testing.T) {
+	t.Parallel()
+
+	config := configotest.New(t, dir, configotest.WithEnv(map[string]string{"APP_ENV": "production"}), configo.WithDeploymentFromEnv("APP_ENV"))
+	assert.Equal(t, "prod.internal", config.MustGetString("db.host"))
+
+	config = configotest.New(t, dir, configotest.WithEnv(map[string]string{"DB_HOST": "env.internal"}))
+	assert.Equal(t, "env.internal", config.MustGetString("db.host"))
+}
+
+func TestOverride(t *testing.T) {
+	t.Parallel()
+
+	config := configotest.New(t, dir)
+
+	t.Run("override", func(t *testing.T) {
+		configotest.Override(t, config, "db.host", "db.test")
+		configotest.Override(t, config, "db.pool.size", 4)
+
+		assert.Equal(t, "db.test", config.MustGetString("db.host"))
+		assert.Equal(t, 4, config.MustGetInt("db.pool.size"))
+	})
+
+	assert.Equal(t, "localhost", config.MustGetString("db.host"))
+
+	_, err := config.Get("db.pool.size")
+	assert.NotNil(t, err)
+}
+
+func TestOverrideNull(t *testing.T) {
+	t.Parallel()
+
+	config := configotest.New(t, fstest.MapFS{
+		"default.yml": {Data: []byte("db:\n  replica: null\n")},
+	})
+
+	t.Run("override", func(t *testing.T) {
+		configotest.Override(t, config, "db.replica", "replica.internal")
+		assert.Equal(t, "replica.internal", config.MustGetString("db.replica"))
+	})
+
+	assert.True(t, config.Has("db.replica"))
+	assert.Nil(t, config.MustGet("db.replica"))
+}
+
+func TestWithGlobal(t *testing.T) {
+	for _, host := range []string{"a.internal", "b.internal", "c.internal"} {
+		host := host
+
+		t.Run(host, func(t *testing.T) {
+			t.Parallel()
+
+			config := configotest.New(t, dir)
+			configotest.Override(t, config, "db.host", host)
+			configotest.WithGlobal(t, config)
+
+			assert.Equal(t, host, configo.MustGetString("db.host"))
+		})
+	}
+}
+
+func TestWithGlobalSubtests(t *testing.T) {
+	parent := configotest.New(t, dir)
+	configotest.WithGlobal(t, parent)
+
+	for _, host := range []string{"a.internal", "b.internal"} {
+		host := host
+
+		t.Run(host, func(t *testing.T) {
+			t.Parallel()
+
+			config := configotest.New(t, dir)
+			configotest.Override(t, config, "db.host", host)
+			configotest.WithGlobal(t, config)
+
+			assert.Equal(t, host, configo.MustGetString("db.host"))
+		})
+	}
+
+	t.Run("nested", func(t *testing.T) {
+		configotest.WithGlobal(t, parent)
+
+		t.Run("child", func(t *testing.T) {
+			config := configotest.New(t, dir)
+			configotest.Override(t, config, "db.host", "child.internal")
+			configotest.WithGlobal(t, config)
+
+			assert.Equal(t, "child.internal", configo.MustGetString("db.host"))
+		})
+
+		assert.Equal(t, "localhost", configo.MustGetString("db.host"))
+	})
+}
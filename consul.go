@@ -0,0 +1,143 @@
+package configo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Consul is a configuration layer read from a prefix of the Consul KV store.
+// Changes are watched using blocking queries
+type Consul struct {
+	kvClient
+	prefix string
+}
+
+// ConsulSource creates a source for the keys under the given prefix, which is a folder:
+// `config/app` reads `config/app/db/host` but not `config/application/name`.
+// The address and token default to the `CONSUL_HTTP_ADDR` and `CONSUL_HTTP_TOKEN`
+// environment variables, read when the source is loaded. See `kvToMap` for how keys and values are mapped
+func ConsulSource(prefix string, opts ...KVOption) *Consul {
+	return &Consul{
+		kvClient: newKVClient("127.0.0.1:8500", "CONSUL_HTTP_ADDR", "CONSUL_HTTP_TOKEN", opts),
+		prefix:   strings.Trim(prefix, "/"),
+	}
+}
+
+type consulPair struct {
+	Key   string
+	Value []byte
+}
+
+// Load reads the keys under the prefix into a nested map
+func (c *Consul) Load(ctx context.Context) (map[string]interface{}, error) {
+	data, _, err := c.LoadPositions(ctx)
+	return data, err
+}
+
+// LoadPositions reads the keys under the prefix along with the key defining each path
+func (c *Consul) LoadPositions(ctx context.Context) (map[string]interface{}, Positions, error) {
+	pairs, _, err := c.list(ctx, 0, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return kvToMap("consul", c.prefix, pairs)
+}
+
+// Watch uses blocking queries to call onChange whenever a key under the prefix changes
+func (c *Consul) Watch(ctx context.Context, onChange func()) error {
+	_, index, err := c.list(ctx, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	for {
+		_, next, err := c.list(ctx, index, 5*time.Minute)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err != nil {
+			if !sleepContext(ctx.Done(), time.Second) {
+				return nil
+			}
+			continue
+		}
+
+		switch {
+		case next < index:
+			// the index went backwards, i.e the raft snapshot was restored
+			index = 0
+		case next > index:
+			index = next
+			onChange()
+		}
+	}
+}
+
+// String describes the source
+func (c *Consul) String() string {
+	return "consul://" + c.prefix
+}
+
+// list returns the pairs under the prefix and the index of the response.
+// When index is not zero the request blocks until the prefix changes or wait elapses
+func (c *Consul) list(ctx context.Context, index uint64, wait time.Duration) ([]kvPair, uint64, error) {
+	query := url.Values{"recurse": {"true"}}
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", wait.String())
+	}
+
+	segments := strings.Split(kvDir(c.prefix), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	address, token := c.connection(ctx)
+
+	endpoint := fmt.Sprintf("%s/v1/kv/%s?%s", address, strings.Join(segments, "/"), query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if token != "" {
+		req.Header.Set("X-Consul-Token", token)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer res.Body.Close()
+
+	next, _ := strconv.ParseUint(res.Header.Get("X-Consul-Index"), 10, 64)
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, next, nil
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("consul responded with %s", res.Status)
+	}
+
+	var entries []consulPair
+	err = json.NewDecoder(res.Body).Decode(&entries)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	pairs := make([]kvPair, len(entries))
+	for i, entry := range entries {
+		pairs[i] = kvPair{entry.Key, entry.Value}
+	}
+
+	return pairs, next, nil
+}
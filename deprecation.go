@@ -0,0 +1,300 @@
+package configo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// deprecatedKey is a path registered with `WithAlias` or `WithDeprecated`
+type deprecatedKey struct {
+	path    string
+	keys    []string
+	alias   string
+	message string
+	// err is the reason the path or alias is invalid, returned by `validateDeprecations`
+	err error
+}
+
+// Deprecation is a deprecated key found while loading the configurations,
+// it is also the error returned by `Initialize` with `WithStrictDeprecations`
+type Deprecation struct {
+	Position
+	// Path is the deprecated path
+	Path string
+	// Alias is the path the value was moved to, empty if the path is not an alias
+	Alias string
+	// Message explains the deprecation
+	Message string
+}
+
+func (d *Deprecation) Error() string {
+	msg := fmt.Sprintf("%s is deprecated", d.Path)
+	if d.Alias != "" {
+		msg += fmt.Sprintf(", use %s", d.Alias)
+	}
+	if d.Message != "" {
+		msg += ": " + d.Message
+	}
+
+	if pos := d.Position.String(); pos != "" {
+		return fmt.Sprintf("%s: %s", pos, msg)
+	}
+
+	return msg
+}
+
+// WithAlias renames oldPath to newPath. Values found under oldPath in files, `env.EXT` mappings
+// and sources are moved to newPath before they are merged and reported as deprecated.
+// If a layer sets both paths the value of newPath is kept.
+// Aliases can be chained, with `a` renamed to `b` and `b` to `c` values of `a` are moved to `c`
+func WithAlias(oldPath, newPath string) ConfigOption {
+	return withDeprecatedKey(oldPath, func(k *deprecatedKey) {
+		p, err := compilePath(newPath)
+		if err == nil && len(p.keys) == 0 {
+			err = errors.New("the path is empty")
+		}
+		if err != nil {
+			k.err = fmt.Errorf("alias of %s: %w", k.path, err)
+			return
+		}
+
+		k.alias = p.canonical
+	})
+}
+
+// WithDeprecated reports the path as deprecated whenever a file, `env.EXT` mapping or source sets it,
+// the message usually tells what to use instead
+func WithDeprecated(path, message string) ConfigOption {
+	return withDeprecatedKey(path, func(k *deprecatedKey) {
+		k.message = message
+	})
+}
+
+// WithDeprecationHandler sets the function called with every deprecated key found by `Initialize`
+// (defaults to logging a warning with the logger set by `WithLogger`)
+func WithDeprecationHandler(handler func(Deprecation)) ConfigOption {
+	return func(c *Config) {
+		c.deprecationHandler = handler
+	}
+}
+
+// WithStrictDeprecations makes `Initialize` fail with a `*Deprecation` on the first deprecated key
+// instead of reporting it, i.e `WithStrictDeprecations(os.Getenv("CI") != "")`
+func WithStrictDeprecations(strict bool) ConfigOption {
+	return func(c *Config) {
+		c.strictDeprecations = strict
+	}
+}
+
+func withDeprecatedKey(path string, set func(k *deprecatedKey)) ConfigOption {
+	return func(c *Config) {
+		path = canonicalPath(path)
+
+		for _, k := range c.deprecatedKeys {
+			if k.path == path {
+				set(k)
+				return
+			}
+		}
+
+		k := &deprecatedKey{path: path}
+
+		keys, err := SplitPath(path)
+		if err == nil && len(keys) == 0 {
+			err = errors.New("the path is empty")
+		}
+		if err != nil {
+			k.err = fmt.Errorf("deprecated key: %w", err)
+		}
+		k.keys = keys
+
+		set(k)
+		c.deprecatedKeys = append(c.deprecatedKeys, k)
+	}
+}
+
+// validateDeprecations checks the paths given to `WithAlias` and `WithDeprecated`
+// and that every alias resolves to a path which is not deprecated
+func (c *Config) validateDeprecations() error {
+	for _, k := range c.deprecatedKeys {
+		if k.err != nil {
+			return k.err
+		}
+	}
+
+	for _, k := range c.deprecatedKeys {
+		if k.alias == "" {
+			continue
+		}
+
+		_, _, err := c.resolveAlias(k)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deprecatedKeyOf returns the deprecated key of the path or of the closest path above it
+func (c *Config) deprecatedKeyOf(path string) *deprecatedKey {
+	var found *deprecatedKey
+	for _, k := range c.deprecatedKeys {
+		if k.err != nil || (path != k.path && !strings.HasPrefix(path, k.path+".")) {
+			continue
+		}
+
+		if found == nil || len(k.path) > len(found.path) {
+			found = k
+		}
+	}
+
+	return found
+}
+
+// resolveAlias follows the aliases from the alias of k, so the order chained aliases are registered in
+// does not matter. It returns the path the values are moved to and the deprecated paths passed on the way
+func (c *Config) resolveAlias(k *deprecatedKey) (string, []string, error) {
+	path := k.alias
+	seen := map[*deprecatedKey]struct{}{k: {}}
+
+	var via []string
+	for {
+		next := c.deprecatedKeyOf(path)
+		if next == nil {
+			return path, via, nil
+		}
+
+		if next.alias == "" {
+			return "", nil, fmt.Errorf("%s is an alias of %s, which is deprecated", k.path, path)
+		}
+
+		if _, found := seen[next]; found {
+			return "", nil, fmt.Errorf("the alias of %s leads back to %s", k.path, next.path)
+		}
+		seen[next] = struct{}{}
+
+		via = append(via, path)
+		path = next.alias + path[len(next.path):]
+	}
+}
+
+// reportDeprecation fails with the deprecation if deprecations are strict, otherwise it is handed to the handler
+func (c *Config) reportDeprecation(d Deprecation) error {
+	if c.strictDeprecations {
+		return &d
+	}
+
+	if c.deprecationHandler != nil {
+		c.deprecationHandler(d)
+		return nil
+	}
+
+	c.logger.Warn("deprecated key", "path", d.Path, "alias", d.Alias, "message", d.Message, "position", d.Position.String())
+	return nil
+}
+
+// migrateDeprecated calls report with the deprecated keys set by the layer
+// and moves the values of aliases, along with their positions, to the new paths
+func (c *Config) migrateDeprecated(data map[string]interface{}, positions Positions, report func(Deprecation) error) error {
+	reported := map[*deprecatedKey]struct{}{}
+
+	// values moved to an alias may hold other deprecated keys, i.e `database.server` moved to `db.server`,
+	// so the keys are checked again until nothing moves and the order they are registered in does not matter
+	for moved := true; moved; {
+		moved = false
+
+		for _, k := range c.deprecatedKeys {
+			if k.err != nil {
+				continue
+			}
+
+			value, found := getIn(data, k.keys)
+			if !found {
+				continue
+			}
+
+			alias, via := "", []string(nil)
+			if k.alias != "" {
+				var err error
+				alias, via, err = c.resolveAlias(k)
+				if err != nil {
+					return err
+				}
+			}
+
+			if _, found := reported[k]; !found {
+				reported[k] = struct{}{}
+
+				err := report(Deprecation{Position: positions[k.path], Path: k.path, Alias: alias, Message: k.message})
+				if err != nil {
+					return err
+				}
+			}
+
+			if alias == "" {
+				continue
+			}
+
+			aliasPath, err := compilePath(alias)
+			if err != nil {
+				return err
+			}
+
+			unsetIn(data, k.keys)
+			moved = true
+
+			// newer paths of the chain are moved to the alias as well and take precedence
+			if anyPathSet(data, append(via, alias)) {
+				movePositions(positions, k.path, "")
+				continue
+			}
+
+			_, err = setIn(data, aliasPath.keys, value)
+			if err != nil {
+				return fmt.Errorf("%s: unable to move %s to %s: %w", positions[k.path], k.path, alias, err)
+			}
+
+			movePositions(positions, k.path, alias)
+		}
+	}
+
+	return nil
+}
+
+// anyPathSet reports whether the data has a value at one of the paths
+func anyPathSet(data map[string]interface{}, paths []string) bool {
+	for _, path := range paths {
+		p, err := compilePath(path)
+		if err != nil {
+			continue
+		}
+
+		if _, exists := getIn(data, p.keys); exists {
+			return true
+		}
+	}
+
+	return false
+}
+
+// movePositions rebases the positions of the path and the paths under it to newPath,
+// or drops them if newPath is empty
+func movePositions(positions Positions, path, newPath string) {
+	moved := Positions{}
+	for p, pos := range positions {
+		if p == path || strings.HasPrefix(p, path+".") {
+			moved[p] = pos
+			delete(positions, p)
+		}
+	}
+
+	if newPath == "" {
+		return
+	}
+
+	for p, pos := range moved {
+		positions[newPath+p[len(path):]] = pos
+	}
+}
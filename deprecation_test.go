@@ -0,0 +1,229 @@
+package configo_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/affanshahid/configo"
+	"github.com/stretchr/testify/assert"
+)
+
+var deprecationDir = fstest.MapFS{
+	"default.yml": {
+		Data: []byte(`
+            db:
+                host: localhost
+                port: 5432
+            cache:
+                ttl: 60
+        `),
+	},
+	"dev.yml": {
+		Data: []byte(`
+            db:
+                addr: dev.internal
+            legacy:
+                workers: 4
+        `),
+	},
+	"local.yml": {
+		Data: []byte(`
+            db:
+                addr: ignored.internal
+                host: local.internal
+        `),
+	},
+	"env.yml": {
+		Data: []byte(`
+            db:
+                addr: DB_ADDR
+        `),
+	},
+}
+
+func TestWithAlias(t *testing.T) {
+	var deprecations []configo.Deprecation
+
+	config, err := configo.NewConfig(
+		deprecationDir,
+		configo.WithEnvLookup(func(name string) (string, bool) {
+			return map[string]string{"DB_ADDR": "env.internal"}[name], name == "DB_ADDR"
+		}),
+		configo.WithAlias("db.addr", "db.host"),
+		configo.WithDeprecated("legacy", "workers are configured per queue"),
+		configo.WithDeprecationHandler(func(d configo.Deprecation) {
+			deprecations = append(deprecations, d)
+		}),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, "env.internal", config.MustGetString("db.host"))
+	assert.False(t, config.Has("db.addr"))
+	assert.Equal(t, 4, config.MustGetInt("legacy.workers"))
+
+	pos, found := config.Position("db.host")
+	assert.True(t, found)
+	assert.Equal(t, "env.yml", pos.File)
+
+	origins := config.Origins("db.host")
+	assert.Len(t, origins, 4)
+	assert.Equal(t, configo.Position{File: "dev.yml", Line: 3, Column: 17}, origins[1])
+
+	assert.Len(t, deprecations, 4)
+	assert.Equal(t, configo.Deprecation{
+		Position: configo.Position{File: "dev.yml", Line: 3, Column: 17},
+		Path:     "db.addr",
+		Alias:    "db.host",
+	}, deprecations[0])
+	assert.Equal(t, "legacy", deprecations[1].Path)
+	assert.Equal(t, "dev.yml:4:13: legacy is deprecated: workers are configured per queue", deprecations[1].Error())
+	assert.Equal(t, "local.yml", deprecations[2].File)
+	assert.Equal(t, "env.yml", deprecations[3].File)
+}
+
+func TestWithStrictDeprecations(t *testing.T) {
+	config, err := configo.NewConfig(
+		deprecationDir,
+		configo.WithEnvLookup(func(string) (string, bool) { return "", false }),
+		configo.WithAlias("db.addr", "db.host"),
+		configo.WithStrictDeprecations(true),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+
+	var deprecation *configo.Deprecation
+	assert.True(t, errors.As(err, &deprecation))
+	assert.Equal(t, "db.addr", deprecation.Path)
+	assert.EqualError(t, err, "dev.yml:3:17: db.addr is deprecated, use db.host")
+}
+
+func TestLintDeprecatedKeys(t *testing.T) {
+	config, err := configo.NewConfig(
+		deprecationDir,
+		configo.WithAlias("db.addr", "db.host"),
+		configo.WithDeprecated("legacy", "workers are configured per queue"),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	issues, err := config.Lint()
+	assert.Nilf(t, err, "err should be nil")
+
+	var rules []string
+	for _, issue := range issues {
+		rules = append(rules, issue.String())
+	}
+
+	assert.Equal(t, []string{
+		"dev.yml:3:17: db.addr is deprecated, use db.host (deprecated-key)",
+		"dev.yml:4:13: legacy is deprecated: workers are configured per queue (deprecated-key)",
+		"dev.yml:4:13: legacy does not exist in the default configurations (orphan-key)",
+		"env.yml:3:17: db.addr is deprecated, use db.host (deprecated-key)",
+		"local.yml:3:17: db.addr is deprecated, use db.host (deprecated-key)",
+	}, rules)
+}
+
+func TestInvalidDeprecations(t *testing.T) {
+	cases := map[string]struct {
+		opts []configo.ConfigOption
+		err  string
+	}{
+		"deprecated path": {
+			[]configo.ConfigOption{configo.WithDeprecated("db..addr", "")},
+			`deprecated key: invalid path "db..addr": empty key at offset 3`,
+		},
+		"old path": {
+			[]configo.ConfigOption{configo.WithAlias("", "db.host")},
+			"deprecated key: the path is empty",
+		},
+		"new path": {
+			[]configo.ConfigOption{configo.WithAlias("db.addr", "db[")},
+			`alias of db.addr: invalid path "db[": unterminated bracket at offset 2`,
+		},
+		"cycle": {
+			[]configo.ConfigOption{configo.WithAlias("db.addr", "db.host"), configo.WithAlias("db.host", "db.addr")},
+			"the alias of db.addr leads back to db.addr",
+		},
+		"deprecated alias": {
+			[]configo.ConfigOption{configo.WithAlias("db.addr", "legacy.addr"), configo.WithDeprecated("legacy", "")},
+			"db.addr is an alias of legacy.addr, which is deprecated",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			config, err := configo.NewConfig(deprecationDir, c.opts...)
+			assert.Nilf(t, err, "err should be nil")
+
+			err = config.Initialize()
+			assert.EqualError(t, err, c.err)
+
+			_, err = config.Lint()
+			assert.EqualError(t, err, c.err)
+		})
+	}
+}
+
+func TestChainedAliases(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                db:
+                    host: localhost
+            `),
+		},
+		"dev.yml": {
+			Data: []byte(`
+                database:
+                    server: dev.internal
+            `),
+		},
+		"local.yml": {
+			Data: []byte(`
+                database:
+                    server: ignored.internal
+                db:
+                    addr: local.internal
+            `),
+		},
+	}
+
+	var deprecations []configo.Deprecation
+
+	config, err := configo.NewConfig(
+		dir,
+		configo.WithAlias("db.server", "db.addr"),
+		configo.WithAlias("db.addr", "db.host"),
+		configo.WithAlias("database", "db"),
+		configo.WithDeprecationHandler(func(d configo.Deprecation) {
+			deprecations = append(deprecations, d)
+		}),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, "local.internal", config.MustGetString("db.host"))
+	assert.Equal(t, []string{"host"}, config.Keys("db"))
+	assert.False(t, config.Has("database"))
+
+	origins := config.Origins("db.host")
+	assert.Len(t, origins, 3)
+	assert.Equal(t, configo.Position{File: "dev.yml", Line: 3, Column: 21}, origins[1])
+
+	var aliases []string
+	for _, d := range deprecations {
+		aliases = append(aliases, d.Path+" -> "+d.Alias)
+	}
+	assert.Equal(t, []string{
+		"database -> db",
+		"db.server -> db.host",
+		"db.addr -> db.host",
+		"database -> db",
+	}, aliases)
+}
@@ -0,0 +1,78 @@
+package configo
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// providerFor returns the built-in provider of a format name or extension, i.e `yaml` or `.yml`
+func providerFor(format string) (Provider, error) {
+	ext := "." + strings.TrimPrefix(strings.ToLower(format), ".")
+
+	provider, found := defaultProviders[ext]
+	if !found {
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+
+	return provider, nil
+}
+
+// encoderFor returns the built-in encoder of a format name or extension, i.e `yaml` or `.yml`
+func encoderFor(format string) (Encoder, error) {
+	provider, err := providerFor(format)
+	if err != nil {
+		return nil, err
+	}
+
+	encoder, ok := provider.(Encoder)
+	if !ok {
+		return nil, fmt.Errorf("format %s cannot be encoded", format)
+	}
+
+	return encoder, nil
+}
+
+// Parse parses the input with the built-in provider of a format,
+// formats are names or extensions i.e `json` or `.yml`
+func Parse(in []byte, format string) (map[string]interface{}, error) {
+	provider, err := providerFor(format)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider.Parse(in)
+}
+
+// Convert parses the input in one format and encodes it in another,
+// formats are names or extensions i.e `json` or `.yml`
+func Convert(in []byte, from, to string) ([]byte, error) {
+	encoder, err := encoderFor(to)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := Parse(in, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return encoder.Encode(data)
+}
+
+// WriteTo writes the loaded configurations in the given format with secrets masked,
+// formats are names or extensions i.e `toml` or `.yml`
+func (c *Config) WriteTo(w io.Writer, format string) (int64, error) {
+	encoder, err := encoderFor(format)
+	if err != nil {
+		return 0, err
+	}
+
+	out, err := encoder.Encode(c.Redacted())
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(out)
+	return int64(n), err
+}
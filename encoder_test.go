@@ -0,0 +1,74 @@
+package configo_test
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	"github.com/affanshahid/configo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvert(t *testing.T) {
+	in := []byte(`{"db": {"host": "localhost", "port": 5432, "replicas": ["a", "b"]}, "debug": true}`)
+
+	for _, format := range []string{"yaml", "yml", "json", "json5", "hjson", "toml"} {
+		t.Run(format, func(t *testing.T) {
+			out, err := configo.Convert(in, "json", format)
+			assert.Nilf(t, err, "err should be nil")
+
+			back, err := configo.Convert(out, format, ".json")
+			assert.Nilf(t, err, "err should be nil")
+
+			dir := fstest.MapFS{"default.json": {Data: back}}
+
+			config, err := configo.NewConfig(dir)
+			assert.Nilf(t, err, "err should be nil")
+
+			err = config.Initialize()
+			assert.Nilf(t, err, "err should be nil")
+
+			assert.Equal(t, "localhost", config.MustGetString("db.host"))
+			assert.Equal(t, 5432, config.MustGetInt("db.port"))
+			assert.Equal(t, []string{"a", "b"}, config.MustGetStringSlice("db.replicas"))
+			assert.Equal(t, true, config.MustGetBool("debug"))
+		})
+	}
+
+	_, err := configo.Convert(in, "json", "xml")
+	assert.EqualError(t, err, "unsupported format: xml")
+}
+
+func TestWriteTo(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                db:
+                    host: localhost
+                    password: hunter2
+                secret:
+                    - db.password
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	var buf bytes.Buffer
+	n, err := config.WriteTo(&buf, "yaml")
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, int64(buf.Len()), n)
+	assert.Equal(t, "db:\n  host: localhost\n  password: '******'\n", buf.String())
+
+	buf.Reset()
+	_, err = config.WriteTo(&buf, "toml")
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, "[db]\nhost = 'localhost'\npassword = '******'\n\n", buf.String())
+
+	_, err = config.WriteTo(&buf, "ini")
+	assert.NotNil(t, err)
+}
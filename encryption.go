@@ -0,0 +1,291 @@
+package configo
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"filippo.io/age"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// EncryptionAge encrypts values for age X25519 recipients.
+	// Encryption keys are recipients (`age1...`) and decryption keys are
+	// identities (`AGE-SECRET-KEY-1...`), both may be newline separated lists
+	EncryptionAge = "age"
+
+	// EncryptionAESGCM encrypts values with AES-256-GCM using a key
+	// derived from a passphrase with scrypt
+	EncryptionAESGCM = "aes-gcm"
+)
+
+const (
+	saltSize  = 16
+	scryptN   = 1 << 15
+	scryptR   = 8
+	scryptP   = 1
+	aesKeyLen = 32
+)
+
+var encryptedValueRegexp = regexp.MustCompile(`^ENC\[([a-z0-9-]+),([A-Za-z0-9+/=]*)\]$`)
+
+// DecryptionError is returned when an encrypted value can not be decrypted
+type DecryptionError struct {
+	Path     string
+	Position Position
+	Err      error
+}
+
+func (e *DecryptionError) Error() string {
+	if pos := e.Position.String(); pos != "" {
+		return fmt.Sprintf("%s: unable to decrypt %s: %v", pos, e.Path, e.Err)
+	}
+
+	return fmt.Sprintf("unable to decrypt %s: %v", e.Path, e.Err)
+}
+
+func (e *DecryptionError) Unwrap() error {
+	return e.Err
+}
+
+// WithDecryptionKey sets the key used to decrypt values encrypted with the given method.
+//
+// Encrypted values take the form `ENC[method,payload]` as produced by `Encrypt`,
+// they are decrypted during `Initialize` and are treated as secrets
+func WithDecryptionKey(method string, key []byte) ConfigOption {
+	return func(c *Config) {
+		c.decryptionKeys[method] = func() ([]byte, error) {
+			return key, nil
+		}
+	}
+}
+
+// WithDecryptionKeyFromFile loads the key for the given method from a file.
+// The file is read during `Initialize`
+func WithDecryptionKeyFromFile(method string, path string) ConfigOption {
+	return func(c *Config) {
+		c.decryptionKeys[method] = func() ([]byte, error) {
+			return os.ReadFile(path)
+		}
+	}
+}
+
+// WithDecryptionKeyFromEnv loads the key for the given method from the given environment variable.
+// The variable is read during `Initialize`, see `WithEnvLookup`
+func WithDecryptionKeyFromEnv(method string, env string) ConfigOption {
+	return func(c *Config) {
+		c.decryptionKeys[method] = func() ([]byte, error) {
+			key, found := c.lookupEnv(env)
+			if !found {
+				return nil, fmt.Errorf("environment variable %s is not set", env)
+			}
+			return []byte(key), nil
+		}
+	}
+}
+
+// Encrypt encrypts the plaintext with the given method and key and returns
+// a value of the form `ENC[method,payload]` which can be placed in a configuration file.
+// For EncryptionAge the key is a list of recipients, for EncryptionAESGCM it is a passphrase
+func Encrypt(method string, key []byte, plaintext string) (string, error) {
+	var payload []byte
+	var err error
+
+	switch method {
+	case EncryptionAge:
+		payload, err = encryptAge(key, []byte(plaintext))
+	case EncryptionAESGCM:
+		payload, err = encryptAESGCM(key, []byte(plaintext))
+	default:
+		err = fmt.Errorf("unknown encryption method %s", method)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("ENC[%s,%s]", method, base64.StdEncoding.EncodeToString(payload)), nil
+}
+
+// Decrypt decrypts a value produced by `Encrypt`
+func Decrypt(value string, key []byte) (string, error) {
+	match := encryptedValueRegexp.FindStringSubmatch(value)
+	if match == nil {
+		return "", errors.New("value is not of the form ENC[method,payload]")
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(match[2])
+	if err != nil {
+		return "", err
+	}
+
+	var plaintext []byte
+	switch match[1] {
+	case EncryptionAge:
+		plaintext, err = decryptAge(key, payload)
+	case EncryptionAESGCM:
+		plaintext, err = decryptAESGCM(key, payload)
+	default:
+		err = fmt.Errorf("unknown encryption method %s", match[1])
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+func encryptAge(key, plaintext []byte) ([]byte, error) {
+	var recipients []age.Recipient
+	for _, line := range strings.Split(string(key), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		recipient, err := age.ParseX25519Recipient(line)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	out := &bytes.Buffer{}
+	w, err := age.Encrypt(out, recipients...)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
+
+func decryptAge(key, payload []byte) ([]byte, error) {
+	identities, err := age.ParseIdentities(bytes.NewReader(key))
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := age.Decrypt(bytes.NewReader(payload), identities...)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
+
+func encryptAESGCM(passphrase, plaintext []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	out := append(salt, nonce...)
+	return gcm.Seal(out, nonce, plaintext, nil), nil
+}
+
+func decryptAESGCM(passphrase, payload []byte) ([]byte, error) {
+	if len(payload) < saltSize {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	salt, payload := payload[:saltSize], payload[saltSize:]
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := payload[:gcm.NonceSize()], payload[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(passphrase, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key(bytes.TrimSpace(passphrase), salt, scryptN, scryptR, scryptP, aesKeyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// decryptValues decrypts all encrypted values in the store in place
+func (c *Config) decryptValues(s *snapshot) error {
+	keys := map[string][]byte{}
+
+	return transformValues(s.store, func(path string, value interface{}) (interface{}, error) {
+		str, ok := value.(string)
+		if !ok {
+			return value, nil
+		}
+
+		match := encryptedValueRegexp.FindStringSubmatch(str)
+		if match == nil {
+			return value, nil
+		}
+
+		decryptionErr := &DecryptionError{Path: path, Position: s.positions[path]}
+
+		method := match[1]
+		key, found := keys[method]
+		if !found {
+			loadKey, found := c.decryptionKeys[method]
+			if !found {
+				decryptionErr.Err = fmt.Errorf("no decryption key for method %s", method)
+				return nil, decryptionErr
+			}
+
+			var err error
+			key, err = loadKey()
+			if err != nil {
+				decryptionErr.Err = err
+				return nil, decryptionErr
+			}
+			keys[method] = key
+		}
+
+		plaintext, err := Decrypt(str, key)
+		if err != nil {
+			decryptionErr.Err = err
+			return nil, decryptionErr
+		}
+
+		s.secrets[path] = struct{}{}
+		return plaintext, nil
+	})
+}
@@ -0,0 +1,131 @@
+package configo_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"filippo.io/age"
+	"github.com/affanshahid/configo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	assert.Nilf(t, err, "err should be nil")
+
+	cases := []struct {
+		method                 string
+		encryptKey, decryptKey []byte
+	}{
+		{configo.EncryptionAge, []byte(identity.Recipient().String()), []byte(identity.String())},
+		{configo.EncryptionAESGCM, []byte("passphrase"), []byte("passphrase\n")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.method, func(t *testing.T) {
+			encrypted, err := configo.Encrypt(tc.method, tc.encryptKey, "hunter2")
+			assert.Nilf(t, err, "err should be nil")
+			assert.Regexp(t, `^ENC\[`+tc.method+`,`, encrypted)
+
+			decrypted, err := configo.Decrypt(encrypted, tc.decryptKey)
+			assert.Nilf(t, err, "err should be nil")
+			assert.Equal(t, "hunter2", decrypted)
+
+			_, err = configo.Decrypt(encrypted, []byte("wrong"))
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestEncryptedValues(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	assert.Nilf(t, err, "err should be nil")
+
+	ageValue, err := configo.Encrypt(configo.EncryptionAge, []byte(identity.Recipient().String()), "hunter2")
+	assert.Nilf(t, err, "err should be nil")
+
+	aesValue, err := configo.Encrypt(configo.EncryptionAESGCM, []byte("passphrase"), "s3cr3t")
+	assert.Nilf(t, err, "err should be nil")
+
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(fmt.Sprintf(`
+                db:
+                    user: admin
+                    password: %s
+                api:
+                    key: %s
+            `, ageValue, aesValue)),
+		},
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "key.txt")
+	err = os.WriteFile(keyFile, []byte(identity.String()), 0600)
+	assert.Nilf(t, err, "err should be nil")
+
+	t.Setenv("CONFIGO_PASSPHRASE", "passphrase")
+
+	config, err := configo.NewConfig(
+		dir,
+		configo.WithDecryptionKeyFromFile(configo.EncryptionAge, keyFile),
+		configo.WithDecryptionKeyFromEnv(configo.EncryptionAESGCM, "CONFIGO_PASSPHRASE"),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, "hunter2", config.MustGetString("db.password"))
+	assert.Equal(t, "s3cr3t", config.MustGetString("api.key"))
+	assert.True(t, config.IsSecret("db.password"))
+	assert.True(t, config.IsSecret("api.key"))
+	assert.False(t, config.IsSecret("db.user"))
+}
+
+func TestDecryptionError(t *testing.T) {
+	value, err := configo.Encrypt(configo.EncryptionAESGCM, []byte("passphrase"), "hunter2")
+	assert.Nilf(t, err, "err should be nil")
+
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(fmt.Sprintf(`
+                db:
+                    password: %s
+            `, value)),
+		},
+	}
+
+	config, err := configo.NewConfig(dir, configo.WithDecryptionKey(configo.EncryptionAESGCM, []byte("wrong")))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	var decryptionErr *configo.DecryptionError
+	assert.True(t, errors.As(err, &decryptionErr))
+	assert.Equal(t, "db.password", decryptionErr.Path)
+	assert.Equal(t, "default.yml:3:21", decryptionErr.Position.String())
+}
+
+func TestMissingDecryptionKey(t *testing.T) {
+	value, err := configo.Encrypt(configo.EncryptionAESGCM, []byte("passphrase"), "hunter2")
+	assert.Nilf(t, err, "err should be nil")
+
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(fmt.Sprintf(`
+                db:
+                    password: %s
+            `, value)),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "db.password")
+}
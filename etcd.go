@@ -0,0 +1,229 @@
+package configo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Etcd is a configuration layer read from a prefix of an etcd v3 cluster.
+// It uses the JSON gateway of etcd and changes are watched using watch streams
+type Etcd struct {
+	kvClient
+	prefix string
+}
+
+// EtcdSource creates a source for the keys under the given prefix, which is a folder:
+// `/config/app` reads `/config/app/db/host` but not `/config/application/name`.
+// The address defaults to the first entry of the `ETCD_ENDPOINTS` environment variable,
+// read when the source is loaded. See `kvToMap` for how keys and values are mapped
+func EtcdSource(prefix string, opts ...KVOption) *Etcd {
+	return &Etcd{
+		kvClient: newKVClient("127.0.0.1:2379", "ETCD_ENDPOINTS", "", opts),
+		prefix:   prefix,
+	}
+}
+
+type etcdKeyValue struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+type etcdHeader struct {
+	Revision string `json:"revision"`
+}
+
+type etcdRangeResponse struct {
+	Header etcdHeader     `json:"header"`
+	Kvs    []etcdKeyValue `json:"kvs"`
+}
+
+type etcdWatchResponse struct {
+	Result struct {
+		Header          etcdHeader        `json:"header"`
+		Canceled        bool              `json:"canceled"`
+		CompactRevision string            `json:"compact_revision"`
+		Events          []json.RawMessage `json:"events"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Load reads the keys under the prefix into a nested map
+func (e *Etcd) Load(ctx context.Context) (map[string]interface{}, error) {
+	data, _, err := e.LoadPositions(ctx)
+	return data, err
+}
+
+// LoadPositions reads the keys under the prefix along with the key defining each path
+func (e *Etcd) LoadPositions(ctx context.Context) (map[string]interface{}, Positions, error) {
+	res := etcdRangeResponse{}
+	err := e.post(ctx, "/v3/kv/range", e.keyRange(), &res)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pairs := make([]kvPair, len(res.Kvs))
+	for i, kv := range res.Kvs {
+		pairs[i] = kvPair{string(kv.Key), kv.Value}
+	}
+
+	return kvToMap("etcd", e.prefix, pairs)
+}
+
+// Watch opens a watch stream and calls onChange whenever a key under the prefix changes.
+// Broken streams are resumed from the last seen revision, or from the compacted revision
+// once the last seen one is compacted
+func (e *Etcd) Watch(ctx context.Context, onChange func()) error {
+	request := e.keyRange()
+	request["count_only"] = true
+
+	res := etcdRangeResponse{}
+	err := e.post(ctx, "/v3/kv/range", request, &res)
+	if err != nil {
+		return err
+	}
+
+	revision, _ := strconv.ParseInt(res.Header.Revision, 10, 64)
+
+	for {
+		revision, err = e.watch(ctx, revision+1, onChange)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err != nil && !sleepContext(ctx.Done(), time.Second) {
+			return nil
+		}
+	}
+}
+
+// watch consumes a single watch stream and returns the last seen revision
+func (e *Etcd) watch(ctx context.Context, start int64, onChange func()) (int64, error) {
+	request := e.keyRange()
+	request["start_revision"] = strconv.FormatInt(start, 10)
+
+	body, err := json.Marshal(map[string]interface{}{"create_request": request})
+	if err != nil {
+		return start - 1, err
+	}
+
+	res, err := e.do(ctx, "/v3/watch", body)
+	if err != nil {
+		return start - 1, err
+	}
+	defer res.Body.Close()
+
+	revision := start - 1
+	dec := json.NewDecoder(res.Body)
+	for {
+		msg := etcdWatchResponse{}
+		err := dec.Decode(&msg)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			return revision, err
+		}
+
+		if msg.Error != nil {
+			return revision, errors.New(msg.Error.Message)
+		}
+
+		if msg.Result.Canceled {
+			// the start revision was compacted, the changes since are lost so they are reported as one
+			if compacted, _ := strconv.ParseInt(msg.Result.CompactRevision, 10, 64); compacted > 0 {
+				onChange()
+				return compacted - 1, nil
+			}
+
+			return revision, errors.New("etcd canceled the watch")
+		}
+
+		if rev, err := strconv.ParseInt(msg.Result.Header.Revision, 10, 64); err == nil && rev > revision {
+			revision = rev
+		}
+
+		if len(msg.Result.Events) > 0 {
+			onChange()
+		}
+	}
+}
+
+// String describes the source
+func (e *Etcd) String() string {
+	return "etcd://" + strings.TrimPrefix(e.prefix, "/")
+}
+
+func (e *Etcd) keyRange() map[string]interface{} {
+	if e.prefix == "" {
+		// the whole keyspace
+		return map[string]interface{}{"key": []byte{0}, "range_end": []byte{0}}
+	}
+
+	dir := kvDir(e.prefix)
+	return map[string]interface{}{"key": []byte(dir), "range_end": prefixEnd(dir)}
+}
+
+func (e *Etcd) post(ctx context.Context, endpoint string, request interface{}, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	res, err := e.do(ctx, endpoint, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	return json.NewDecoder(res.Body).Decode(response)
+}
+
+func (e *Etcd) do(ctx context.Context, endpoint string, body []byte) (*http.Response, error) {
+	address, token := e.connection(ctx)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, address+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	res, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("etcd responded with %s", res.Status)
+	}
+
+	return res, nil
+}
+
+// prefixEnd returns the end of the range covering all keys with the given prefix
+func prefixEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+
+	// the prefix is all 0xff, range to the end of the keyspace
+	return []byte{0}
+}
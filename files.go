@@ -1,6 +1,11 @@
 package configo
 
 import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
 	"strings"
 )
 
@@ -34,6 +39,129 @@ var defaultProviders = map[string]Provider{
 	".toml":  tomlProvider,
 }
 
+// extensionOrder is the order in which files sharing a basename are merged,
+// later files override earlier ones
+var extensionOrder = []string{".json", ".json5", ".hjson", ".toml", ".yaml", ".yml"}
+
+// Extensions returns the supported file extensions in the order files sharing a basename are merged
+func Extensions() []string {
+	return append([]string(nil), extensionOrder...)
+}
+
+// DuplicatePolicy decides how files sharing a basename with different extensions
+// are handled, i.e `default.json` and `default.yml`
+type DuplicatePolicy int
+
+const (
+	// DuplicateMerge merges all the files in the order
+	// `json`, `json5`, `hjson`, `toml`, `yaml`, `yml`
+	DuplicateMerge DuplicatePolicy = iota
+	// DuplicateError makes `Initialize` fail
+	DuplicateError
+)
+
+// WithDuplicatePolicy sets how files sharing a basename are handled (defaults to `DuplicateMerge`)
+func WithDuplicatePolicy(policy DuplicatePolicy) ConfigOption {
+	return func(c *Config) {
+		c.duplicatePolicy = policy
+	}
+}
+
+// formatNames maps extensions to the name of their format
+var formatNames = map[string]string{
+	".yaml":  "yaml",
+	".yml":   "yaml",
+	".json":  "json",
+	".json5": "json5",
+	".hjson": "hjson",
+	".toml":  "toml",
+}
+
+// LoadedFile is a file merged by `Initialize`
+type LoadedFile struct {
+	// Name is the name of the file in the directory
+	Name string
+	// Template is the template which matched the file, i.e `{deployment}-{instance}` or `env`
+	Template string
+	// Format is the format the file was parsed with, i.e `yaml`
+	Format string
+	// Size is the size of the file in bytes
+	Size int64
+	// Hash is the hex encoded SHA-256 of the file contents
+	Hash string
+}
+
+// LoadedFiles returns the files merged by the last successful `Initialize` in the order they were merged
+func (c *Config) LoadedFiles() []LoadedFile {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return append([]LoadedFile(nil), c.files...)
+}
+
+// readDir groups the configuration files of the directory by their name without the extension,
+// the files of each group are in the order they should be merged
+func (c *Config) readDir() (map[string][]fs.DirEntry, error) {
+	if c.dir == nil {
+		return nil, errors.New("no directory to load the configurations from")
+	}
+
+	files, err := fs.ReadDir(c.dir, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	fileMap := map[string][]fs.DirEntry{}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		ext := filepath.Ext(file.Name())
+		if _, supported := defaultProviders[strings.ToLower(ext)]; !supported {
+			c.logger.Debug("skipped file", "file", file.Name(), "reason", "unsupported extension")
+			continue
+		}
+
+		nameWithoutExt := strings.TrimSuffix(file.Name(), ext)
+		fileMap[nameWithoutExt] = append(fileMap[nameWithoutExt], file)
+	}
+
+	for _, entries := range fileMap {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return extensionRank(entries[i].Name()) < extensionRank(entries[j].Name())
+		})
+	}
+
+	return fileMap, nil
+}
+
+// checkDuplicates applies the duplicate policy to the files of a basename
+func (c *Config) checkDuplicates(basename string, entries []fs.DirEntry) error {
+	if len(entries) < 2 || c.duplicatePolicy != DuplicateError {
+		return nil
+	}
+
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+
+	return fmt.Errorf("multiple files named %s: %s", basename, strings.Join(names, ", "))
+}
+
+func extensionRank(name string) int {
+	ext := strings.ToLower(filepath.Ext(name))
+	for i, e := range extensionOrder {
+		if e == ext {
+			return i
+		}
+	}
+
+	return len(extensionOrder)
+}
+
 func getExpectedBasename(tmpl string, env environment) (ret string) {
 	ret = strings.Replace(tmpl, "{deployment}", env.deployment, 1)
 	ret = strings.Replace(ret, "{instance}", env.instance, 1)
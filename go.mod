@@ -1,8 +1,11 @@
 module github.com/affanshahid/configo
 
-go 1.17
+// log/slog (WithLogger) requires go 1.21, filippo.io/age requires go 1.19
+go 1.21
 
 require (
+	filippo.io/age v1.2.1
+	github.com/PaesslerAG/gval v1.0.0
 	github.com/PaesslerAG/jsonpath v0.1.1
 	github.com/affanshahid/walkmap v1.0.2
 	github.com/flynn/json5 v0.0.0-20160717195620-7620272ed633
@@ -11,14 +14,15 @@ require (
 	github.com/pelletier/go-toml/v2 v2.0.0-beta.4
 	github.com/spf13/cast v1.4.1
 	github.com/stretchr/testify v1.7.1-0.20210427113832-6241f9ab9942
+	golang.org/x/crypto v0.24.0
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
 
 require (
-	github.com/PaesslerAG/gval v1.0.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/kylelemons/godebug v1.1.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/robertkrimen/otto v0.0.0-20211024170158-b87d35c0b86f // indirect
+	golang.org/x/sys v0.21.0 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
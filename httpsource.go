@@ -0,0 +1,392 @@
+package configo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var contentTypeFormats = map[string]string{
+	"application/json":   ".json",
+	"text/json":          ".json",
+	"application/json5":  ".json5",
+	"application/hjson":  ".hjson",
+	"application/yaml":   ".yaml",
+	"application/x-yaml": ".yaml",
+	"text/yaml":          ".yaml",
+	"text/x-yaml":        ".yaml",
+	"application/toml":   ".toml",
+	"text/toml":          ".toml",
+}
+
+// HTTP is a configuration layer fetched from an HTTP(S) endpoint.
+//
+// The format of the document is taken from the Content-Type of the response,
+// falling back to the extension of the URL path. Changes are detected by polling
+// with `If-None-Match` when the endpoint returns an ETag.
+//
+// When a cache file is set every fetched document which parses is written to it,
+// and it is used in place of the endpoint when the endpoint can not be reached or
+// responds with a server error. Client errors such as 401, 403 and 404 are never hidden by the cache
+type HTTP struct {
+	url          string
+	client       *http.Client
+	headers      http.Header
+	format       string
+	cacheFile    string
+	pollInterval time.Duration
+	logger       *slog.Logger
+
+	mu   sync.Mutex
+	last *httpDocument
+}
+
+// httpDocument is the last fetched document, it is also the format of the cache file
+type httpDocument struct {
+	ETag   string `json:"etag,omitempty"`
+	Format string `json:"format"`
+	Body   []byte `json:"body"`
+}
+
+// httpStatusError is returned when the endpoint responds with an unexpected status
+type httpStatusError struct {
+	url    string
+	status string
+	code   int
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("%s responded with %s", e.url, e.status)
+}
+
+// canFallback reports whether the cache may be used in place of the endpoint,
+// only when the endpoint can not be reached or fails with a server error
+func canFallback(err error) bool {
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= http.StatusInternalServerError
+	}
+
+	return true
+}
+
+// HTTPOption is a functional option to configure an HTTP source
+type HTTPOption func(*HTTP)
+
+// HTTPSource creates a source which fetches the document at the given URL
+func HTTPSource(url string, opts ...HTTPOption) *HTTP {
+	h := &HTTP{
+		url:          url,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		headers:      http.Header{},
+		pollInterval: 30 * time.Second,
+		logger:       slog.New(discardHandler{}),
+	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// WithHTTPClient sets the client used for requests
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(h *HTTP) {
+		h.client = client
+	}
+}
+
+// WithHTTPTimeout sets the timeout of every request (defaults to 10s)
+func WithHTTPTimeout(timeout time.Duration) HTTPOption {
+	return func(h *HTTP) {
+		client := *h.client
+		client.Timeout = timeout
+		h.client = &client
+	}
+}
+
+// WithHTTPHeader adds a header to every request, i.e for authentication
+func WithHTTPHeader(key, value string) HTTPOption {
+	return func(h *HTTP) {
+		h.headers.Add(key, value)
+	}
+}
+
+// WithHTTPFormat sets the format (`yaml`, `json` etc) of the document
+// instead of deriving it from the response
+func WithHTTPFormat(format string) HTTPOption {
+	return func(h *HTTP) {
+		h.format = "." + strings.TrimPrefix(strings.ToLower(format), ".")
+	}
+}
+
+// WithHTTPCache sets the file to write the last successfully fetched document to
+func WithHTTPCache(file string) HTTPOption {
+	return func(h *HTTP) {
+		h.cacheFile = file
+	}
+}
+
+// WithHTTPPollInterval sets how often `Watch` checks the endpoint for changes (defaults to 30s)
+func WithHTTPPollInterval(interval time.Duration) HTTPOption {
+	return func(h *HTTP) {
+		h.pollInterval = interval
+	}
+}
+
+// WithHTTPLogger logs when the cache is served in place of the endpoint,
+// when the cache can not be written and when polling fails
+func WithHTTPLogger(logger *slog.Logger) HTTPOption {
+	return func(h *HTTP) {
+		if logger == nil {
+			logger = slog.New(discardHandler{})
+		}
+		h.logger = logger
+	}
+}
+
+// Load fetches and parses the document
+func (h *HTTP) Load(ctx context.Context) (map[string]interface{}, error) {
+	data, _, err := h.LoadPositions(ctx)
+	return data, err
+}
+
+// LoadPositions fetches and parses the document along with the position of each key
+func (h *HTTP) LoadPositions(ctx context.Context) (map[string]interface{}, Positions, error) {
+	doc, _, err := h.fetch(ctx)
+	if err != nil {
+		return h.fallback(err)
+	}
+
+	data, positions, err := h.parse(doc)
+	if err != nil {
+		// an invalid document never replaces the last known good one
+		return nil, nil, err
+	}
+
+	h.commit(doc)
+	return data, positions, nil
+}
+
+// Watch polls the endpoint and calls onChange when the document changed
+func (h *HTTP) Watch(ctx context.Context, onChange func()) error {
+	if h.pollInterval <= 0 {
+		return fmt.Errorf("invalid poll interval %s, it must be positive", h.pollInterval)
+	}
+
+	ticker := time.NewTicker(h.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			doc, changed, err := h.fetch(ctx)
+			if err != nil {
+				// keep serving the last known good document
+				h.logger.Warn("unable to poll the configurations", "url", h.url, "error", err)
+				continue
+			}
+
+			if !changed {
+				continue
+			}
+
+			_, _, err = h.parse(doc)
+			if err != nil {
+				h.logger.Warn("ignoring an invalid document", "url", h.url, "error", err)
+				continue
+			}
+
+			h.commit(doc)
+			onChange()
+		}
+	}
+}
+
+// String describes the source
+func (h *HTTP) String() string {
+	return h.url
+}
+
+// fetch requests the document, reusing the last fetched document if it is unchanged.
+// It reports whether the document changed since the last fetch, the document is not kept until it is committed
+func (h *HTTP) fetch(ctx context.Context) (*httpDocument, bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for key, values := range h.headers {
+		req.Header[key] = values
+	}
+
+	if h.last != nil && h.last.ETag != "" {
+		req.Header.Set("If-None-Match", h.last.ETag)
+	}
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified && h.last != nil {
+		return h.last, false, nil
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, false, &httpStatusError{url: h.url, status: res.Status, code: res.StatusCode}
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, false, err
+	}
+
+	doc := &httpDocument{
+		ETag:   res.Header.Get("ETag"),
+		Format: h.detectFormat(res),
+		Body:   body,
+	}
+
+	changed := h.last == nil || string(h.last.Body) != string(doc.Body) || h.last.Format != doc.Format
+	return doc, changed, nil
+}
+
+// parse parses the document along with the position of each key
+func (h *HTTP) parse(doc *httpDocument) (map[string]interface{}, Positions, error) {
+	provider, found := defaultProviders[doc.Format]
+	if !found {
+		return nil, nil, fmt.Errorf("unsupported format %q", doc.Format)
+	}
+
+	data, positions, err := parseWith(provider, doc.Body)
+	if err != nil {
+		return nil, nil, withFile(err, h.url)
+	}
+
+	for p, pos := range positions {
+		pos.File = h.url
+		positions[p] = pos
+	}
+
+	return data, positions, nil
+}
+
+// commit keeps the parsed document as the last known good one and writes it to the cache
+func (h *HTTP) commit(doc *httpDocument) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.last == doc {
+		return
+	}
+	h.last = doc
+
+	if h.cacheFile != "" {
+		// the document was fetched, a cache which can not be written only matters once the endpoint is down
+		err := writeHTTPCache(h.cacheFile, doc)
+		if err != nil {
+			h.logger.Warn("unable to write the cache", "url", h.url, "file", h.cacheFile, "error", err)
+		}
+	}
+}
+
+// fallback parses the last known good document when the endpoint can not be reached
+// or fails with a server error
+func (h *HTTP) fallback(fetchErr error) (map[string]interface{}, Positions, error) {
+	if !canFallback(fetchErr) {
+		return nil, nil, fetchErr
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.last != nil {
+		h.logger.Warn("serving the last fetched document", "url", h.url, "error", fetchErr)
+		return h.parse(h.last)
+	}
+
+	if h.cacheFile == "" {
+		return nil, nil, fetchErr
+	}
+
+	in, err := os.ReadFile(h.cacheFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w (no usable cache: %v)", fetchErr, err)
+	}
+
+	doc := &httpDocument{}
+	err = json.Unmarshal(in, doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w (no usable cache: %v)", fetchErr, err)
+	}
+
+	data, positions, err := h.parse(doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w (no usable cache: %v)", fetchErr, err)
+	}
+
+	h.logger.Warn("serving the cached document", "url", h.url, "file", h.cacheFile, "error", fetchErr)
+	h.last = doc
+	return data, positions, nil
+}
+
+func (h *HTTP) detectFormat(res *http.Response) string {
+	if h.format != "" {
+		return h.format
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(res.Header.Get("Content-Type")); err == nil {
+		if format, found := contentTypeFormats[mediaType]; found {
+			return format
+		}
+	}
+
+	if u, err := url.Parse(h.url); err == nil {
+		return strings.ToLower(path.Ext(u.Path))
+	}
+
+	return ""
+}
+
+func writeHTTPCache(file string, doc *httpDocument) error {
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(out)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
@@ -0,0 +1,370 @@
+package configo_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/affanshahid/configo"
+	"github.com/stretchr/testify/assert"
+)
+
+type configServer struct {
+	mu          sync.Mutex
+	contentType string
+	body        string
+	etag        string
+	down        bool
+	status      int
+	notModified int
+}
+
+func (s *configServer) set(body, etag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body, s.etag = body, etag
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.down {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+
+	if s.etag != "" && r.Header.Get("If-None-Match") == s.etag {
+		s.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", s.contentType)
+	w.Header().Set("ETag", s.etag)
+	w.Write([]byte(s.body))
+}
+
+func TestHTTPSource(t *testing.T) {
+	cases := []struct {
+		contentType, path, body string
+	}{
+		{"application/json; charset=utf-8", "/config", `{"db": {"host": "remote"}}`},
+		{"application/x-yaml", "/config", "db:\n  host: remote\n"},
+		{"text/plain", "/config.toml", "[db]\nhost = \"remote\"\n"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.contentType, func(t *testing.T) {
+			server := httptest.NewServer(&configServer{contentType: tc.contentType, body: tc.body})
+			defer server.Close()
+
+			dir := fstest.MapFS{
+				"default.yml": {
+					Data: []byte(`
+                        db:
+                            host: localhost
+                            port: 5432
+                    `),
+				},
+			}
+
+			config, err := configo.NewConfig(
+				dir,
+				configo.WithSource(configo.HTTPSource(server.URL+tc.path), configo.AfterFiles),
+			)
+			assert.Nilf(t, err, "err should be nil")
+
+			err = config.Initialize()
+			assert.Nilf(t, err, "err should be nil")
+
+			assert.Equal(t, "remote", config.MustGetString("db.host"))
+			assert.Equal(t, 5432, config.MustGetInt("db.port"))
+
+			pos, _ := config.Position("db.host")
+			assert.Equal(t, server.URL+tc.path, pos.File)
+		})
+	}
+}
+
+func TestHTTPSourceWatch(t *testing.T) {
+	backend := &configServer{contentType: "application/json", body: `{"p1": "foo"}`, etag: `"v1"`}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	source := configo.HTTPSource(server.URL, configo.WithHTTPPollInterval(10*time.Millisecond))
+
+	config, err := configo.NewConfig(fstest.MapFS{}, configo.WithSource(source, configo.AfterFiles))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, "foo", config.MustGetString("p1"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan error, 1)
+	go config.Watch(ctx, func(err error) {
+		reloaded <- err
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	backend.set(`{"p1": "bar"}`, `"v2"`)
+
+	select {
+	case err := <-reloaded:
+		assert.Nilf(t, err, "err should be nil")
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+
+	assert.Equal(t, "bar", config.MustGetString("p1"))
+
+	backend.mu.Lock()
+	assert.Greater(t, backend.notModified, 0)
+	backend.mu.Unlock()
+}
+
+func TestHTTPSourceCache(t *testing.T) {
+	backend := &configServer{contentType: "application/json", body: `{"p1": "foo"}`}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	cacheFile := filepath.Join(t.TempDir(), "config.cache")
+
+	config, err := configo.NewConfig(
+		fstest.MapFS{},
+		configo.WithSource(configo.HTTPSource(server.URL, configo.WithHTTPCache(cacheFile)), configo.AfterFiles),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	backend.mu.Lock()
+	backend.down = true
+	backend.mu.Unlock()
+
+	// a fresh process only has the cache file to fall back on
+	config, err = configo.NewConfig(
+		fstest.MapFS{},
+		configo.WithSource(configo.HTTPSource(server.URL, configo.WithHTTPCache(cacheFile)), configo.AfterFiles),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, "foo", config.MustGetString("p1"))
+
+	config, err = configo.NewConfig(
+		fstest.MapFS{},
+		configo.WithSource(configo.HTTPSource(server.URL), configo.AfterFiles),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.NotNil(t, err)
+}
+
+func TestHTTPSourceCacheFallback(t *testing.T) {
+	backend := &configServer{contentType: "application/json", body: `{"p1": "foo"}`}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	cacheFile := filepath.Join(t.TempDir(), "config.cache")
+
+	var logs bytes.Buffer
+	newConfig := func() *configo.Config {
+		source := configo.HTTPSource(
+			server.URL,
+			configo.WithHTTPCache(cacheFile),
+			configo.WithHTTPLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		)
+
+		config, err := configo.NewConfig(fstest.MapFS{}, configo.WithSource(source, configo.AfterFiles))
+		assert.Nilf(t, err, "err should be nil")
+		return config
+	}
+
+	err := newConfig().Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	backend.mu.Lock()
+	backend.down = true
+	backend.mu.Unlock()
+
+	err = newConfig().Initialize()
+	assert.Nilf(t, err, "err should be nil")
+	assert.Contains(t, logs.String(), `msg="serving the cached document"`)
+	assert.Contains(t, logs.String(), "503 Service Unavailable")
+
+	backend.mu.Lock()
+	backend.down = false
+	backend.status = http.StatusForbidden
+	backend.mu.Unlock()
+
+	err = newConfig().Initialize()
+	assert.ErrorContains(t, err, "responded with 403 Forbidden")
+}
+
+func TestHTTPSourceInvalidDocument(t *testing.T) {
+	backend := &configServer{contentType: "application/json", body: `{"p1": "foo"}`, etag: `"v1"`}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	cacheFile := filepath.Join(t.TempDir(), "config.cache")
+	source := configo.HTTPSource(server.URL, configo.WithHTTPCache(cacheFile))
+
+	config, err := configo.NewConfig(fstest.MapFS{}, configo.WithSource(source, configo.AfterFiles))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	backend.set(`{"p1": `, `"v2"`)
+
+	err = config.Reload()
+	assert.ErrorContains(t, err, "unexpected end of JSON input")
+
+	backend.mu.Lock()
+	backend.status = http.StatusInternalServerError
+	backend.mu.Unlock()
+
+	// the same source serves the last good document
+	err = config.Reload()
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, "foo", config.MustGetString("p1"))
+
+	// and a fresh process the cached one
+	config, err = configo.NewConfig(
+		fstest.MapFS{},
+		configo.WithSource(configo.HTTPSource(server.URL, configo.WithHTTPCache(cacheFile)), configo.AfterFiles),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, "foo", config.MustGetString("p1"))
+}
+
+func TestHTTPSourceWatchInvalidDocument(t *testing.T) {
+	backend := &configServer{contentType: "application/json", body: `{"p1": "foo"}`, etag: `"v1"`}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	source := configo.HTTPSource(server.URL, configo.WithHTTPPollInterval(10*time.Millisecond))
+
+	config, err := configo.NewConfig(fstest.MapFS{}, configo.WithSource(source, configo.AfterFiles))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan error, 1)
+	go config.Watch(ctx, func(err error) {
+		reloaded <- err
+	})
+
+	backend.set(`{"p1": `, `"v2"`)
+
+	select {
+	case err := <-reloaded:
+		t.Fatalf("invalid document was reported as a change: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	backend.set(`{"p1": "bar"}`, `"v3"`)
+
+	select {
+	case err := <-reloaded:
+		assert.Nilf(t, err, "err should be nil")
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+
+	assert.Equal(t, "bar", config.MustGetString("p1"))
+}
+
+func TestHTTPSourceCacheWriteError(t *testing.T) {
+	backend := &configServer{contentType: "application/json", body: `{"p1": "foo"}`, etag: `"v1"`}
+	server := httptest.NewServer(backend)
+	defer server.Close()
+
+	var logs bytes.Buffer
+	source := configo.HTTPSource(
+		server.URL,
+		configo.WithHTTPPollInterval(10*time.Millisecond),
+		configo.WithHTTPCache(filepath.Join(t.TempDir(), "missing", "config.cache")),
+		configo.WithHTTPLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+	)
+
+	config, err := configo.NewConfig(fstest.MapFS{}, configo.WithSource(source, configo.AfterFiles))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+	assert.Contains(t, logs.String(), `msg="unable to write the cache"`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan error, 1)
+	go config.Watch(ctx, func(err error) {
+		reloaded <- err
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	backend.set(`{"p1": "bar"}`, `"v2"`)
+
+	select {
+	case err := <-reloaded:
+		assert.Nilf(t, err, "err should be nil")
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+
+	assert.Equal(t, "bar", config.MustGetString("p1"))
+}
+
+func TestHTTPSourceInvalidPollInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Second} {
+		source := configo.HTTPSource("http://127.0.0.1", configo.WithHTTPPollInterval(interval))
+
+		err := source.Watch(context.Background(), func() {})
+		assert.EqualError(t, err, fmt.Sprintf("invalid poll interval %s, it must be positive", interval))
+	}
+}
+
+func TestHTTPSourceTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	config, err := configo.NewConfig(
+		fstest.MapFS{},
+		configo.WithSource(configo.HTTPSource(server.URL, configo.WithHTTPTimeout(10*time.Millisecond)), configo.AfterFiles),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,103 @@
+package configo
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/PaesslerAG/gval"
+	"github.com/PaesslerAG/jsonpath"
+)
+
+// maxCacheSize bounds the caches of compiled paths and queries,
+// a full cache is cleared so paths built at runtime cannot grow it forever
+const maxCacheSize = 4096
+
+// buildIndex maps the canonical path of every value, including maps, slices and the root,
+// to the value so lookups are a single map read
+func buildIndex(store map[string]interface{}) map[string]interface{} {
+	if store == nil {
+		return nil
+	}
+
+	index := map[string]interface{}{}
+
+	var walk func(value interface{}, path string)
+	walk = func(value interface{}, path string) {
+		index[path] = value
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, val := range v {
+				walk(val, joinKeyPath(path, key))
+			}
+		case []interface{}:
+			for i, val := range v {
+				walk(val, joinKeyPath(path, strconv.Itoa(i)))
+			}
+		}
+	}
+	walk(store, "")
+
+	return index
+}
+
+// cache is a bounded concurrent cache
+type cache[T interface{}] struct {
+	mu      sync.RWMutex
+	entries map[string]T
+}
+
+func (c *cache[T]) get(key string, compute func(string) (T, error)) (T, error) {
+	c.mu.RLock()
+	value, found := c.entries[key]
+	c.mu.RUnlock()
+
+	if found {
+		return value, nil
+	}
+
+	value, err := compute(key)
+	if err != nil {
+		return value, err
+	}
+
+	c.mu.Lock()
+	if c.entries == nil || len(c.entries) >= maxCacheSize {
+		c.entries = map[string]T{}
+	}
+	c.entries[key] = value
+	c.mu.Unlock()
+
+	return value, nil
+}
+
+// compiledPath is a parsed path
+type compiledPath struct {
+	keys []string
+	// canonical is the form used by the index, positions and secrets
+	canonical string
+}
+
+var (
+	pathCache  cache[compiledPath]
+	queryCache cache[gval.Evaluable]
+)
+
+// compilePath parses a path, parsed paths are cached
+func compilePath(path string) (compiledPath, error) {
+	return pathCache.get(path, func(path string) (compiledPath, error) {
+		keys, err := SplitPath(path)
+		if err != nil {
+			return compiledPath{}, err
+		}
+
+		return compiledPath{keys, JoinPath(keys...)}, nil
+	})
+}
+
+// compileQuery returns the evaluable of a JSONPath expression
+func compileQuery(expr string) (gval.Evaluable, error) {
+	return queryCache.get(expr, func(expr string) (gval.Evaluable, error) {
+		return jsonpath.New(expr)
+	})
+}
@@ -0,0 +1,228 @@
+package configo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"strings"
+	"time"
+)
+
+// KeyPerFile is a configuration layer read from a directory where every file name
+// is a key and the contents of the file are its value, such as Kubernetes ConfigMaps
+// and Secrets mounted as volumes.
+//
+// Dots in file names denote nesting, i.e a file named `db.host` sets `{prefix}.db.host`.
+// Trailing newlines are removed from values. Hidden entries such as the `..data`
+// symlink Kubernetes uses to swap contents atomically are skipped, and since files
+// are read on every load `Reload` picks up updated contents.
+//
+// KeyPerFile implements WatchableSource by polling the directory for changes
+type KeyPerFile struct {
+	dir          fs.FS
+	prefix       string
+	formats      map[string]string
+	pollInterval time.Duration
+}
+
+// KeyPerFileOption is a functional option to configure a KeyPerFile source
+type KeyPerFileOption func(*KeyPerFile)
+
+// KeyPerFileSource creates a KeyPerFile source which places the keys read
+// from dir under the given dotted prefix, an empty prefix places them at the root
+func KeyPerFileSource(dir fs.FS, prefix string, opts ...KeyPerFileOption) *KeyPerFile {
+	k := &KeyPerFile{dir: dir, prefix: prefix, formats: map[string]string{}, pollInterval: 10 * time.Second}
+
+	for _, opt := range opts {
+		opt(k)
+	}
+
+	return k
+}
+
+// WithKeyFormat parses the file with the given name using the provider for the given
+// format (`yaml`, `json` etc) instead of using its contents as a string.
+// The extension matching the format is dropped from the key
+func WithKeyFormat(name string, format string) KeyPerFileOption {
+	return func(k *KeyPerFile) {
+		k.formats[name] = "." + strings.TrimPrefix(strings.ToLower(format), ".")
+	}
+}
+
+// WithKeyPerFilePollInterval sets how often `Watch` checks the directory for changes (defaults to 10s)
+func WithKeyPerFilePollInterval(interval time.Duration) KeyPerFileOption {
+	return func(k *KeyPerFile) {
+		k.pollInterval = interval
+	}
+}
+
+// WithKeyPerFile merges the given source after the configuration files
+// and before the environment variable overrides.
+// It is the same as `WithSource(source, AfterFiles)`
+func WithKeyPerFile(source *KeyPerFile) ConfigOption {
+	return WithSource(source, AfterFiles)
+}
+
+// Load reads the directory into a nested map
+func (k *KeyPerFile) Load(ctx context.Context) (map[string]interface{}, error) {
+	data, _, err := k.LoadPositions(ctx)
+	return data, err
+}
+
+// LoadPositions reads the directory into a nested map along with the file defining each key
+func (k *KeyPerFile) LoadPositions(ctx context.Context) (map[string]interface{}, Positions, error) {
+	entries, err := fs.ReadDir(k.dir, ".")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data := map[string]interface{}{}
+	positions := Positions{}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		info, err := fs.Stat(k.dir, name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if info.IsDir() {
+			continue
+		}
+
+		in, err := fs.ReadFile(k.dir, name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		key := name
+		var value interface{} = strings.TrimRight(string(in), "\r\n")
+		var filePositions Positions
+
+		if ext, found := k.formats[name]; found {
+			provider, found := defaultProviders[ext]
+			if !found {
+				return nil, nil, fmt.Errorf("%s: unknown format %s", name, strings.TrimPrefix(ext, "."))
+			}
+
+			parsed, parsedPositions, err := parseWith(provider, in)
+			if err != nil {
+				return nil, nil, withFile(err, name)
+			}
+
+			key = strings.TrimSuffix(name, ext)
+			value = parsed
+			filePositions = parsedPositions
+		}
+
+		keys := append(k.prefixKeys(), strings.Split(key, ".")...)
+		keyPath := JoinPath(keys...)
+
+		for p, pos := range filePositions {
+			pos.File = name
+			positions[joinPaths(keyPath, p)] = pos
+		}
+
+		positions[keyPath] = Position{File: name}
+		setNested(data, keys, value)
+	}
+
+	return data, positions, nil
+}
+
+// prefixKeys splits the prefix, invalid prefixes are split at dots
+func (k *KeyPerFile) prefixKeys() []string {
+	if k.prefix == "" {
+		return nil
+	}
+
+	keys, err := SplitPath(k.prefix)
+	if err != nil {
+		return strings.Split(k.prefix, ".")
+	}
+
+	return keys
+}
+
+// setNested sets the value at the given keys creating intermediate maps as necessary
+func setNested(data map[string]interface{}, keys []string, value interface{}) {
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := data[key].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			data[key] = next
+		}
+		data = next
+	}
+
+	data[keys[len(keys)-1]] = value
+}
+
+// Watch polls the directory and calls onChange when the contents of any file changed
+func (k *KeyPerFile) Watch(ctx context.Context, onChange func()) error {
+	if k.pollInterval <= 0 {
+		return fmt.Errorf("invalid poll interval %s, it must be positive", k.pollInterval)
+	}
+
+	last, err := k.fingerprint()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(k.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			current, err := k.fingerprint()
+			if err != nil {
+				// the directory might be in the middle of an update
+				continue
+			}
+
+			if current != last {
+				last = current
+				onChange()
+			}
+		}
+	}
+}
+
+// fingerprint hashes the names and contents of all files in the directory
+func (k *KeyPerFile) fingerprint() (string, error) {
+	entries, err := fs.ReadDir(k.dir, ".")
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		in, err := fs.ReadFile(k.dir, entry.Name())
+		if err != nil {
+			continue
+		}
+
+		fmt.Fprintf(h, "%s\x00%d\x00", entry.Name(), len(in))
+		h.Write(in)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// String describes the source
+func (k *KeyPerFile) String() string {
+	return "key-per-file source " + k.prefix
+}
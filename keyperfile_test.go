@@ -0,0 +1,129 @@
+package configo_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/affanshahid/configo"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeConfigMap lays out files the way kubelet mounts a ConfigMap volume
+func writeConfigMap(t *testing.T, dir string, revision string, files map[string]string) {
+	revisionDir := filepath.Join(dir, ".."+revision)
+	err := os.Mkdir(revisionDir, 0755)
+	assert.Nilf(t, err, "err should be nil")
+
+	for name, content := range files {
+		err = os.WriteFile(filepath.Join(revisionDir, name), []byte(content), 0644)
+		assert.Nilf(t, err, "err should be nil")
+	}
+
+	tmpLink := filepath.Join(dir, "..data_tmp")
+	err = os.Symlink(".."+revision, tmpLink)
+	assert.Nilf(t, err, "err should be nil")
+	err = os.Rename(tmpLink, filepath.Join(dir, "..data"))
+	assert.Nilf(t, err, "err should be nil")
+
+	for name := range files {
+		link := filepath.Join(dir, name)
+		if _, err := os.Lstat(link); err == nil {
+			continue
+		}
+		err = os.Symlink(filepath.Join("..data", name), link)
+		assert.Nilf(t, err, "err should be nil")
+	}
+}
+
+func TestKeyPerFileSource(t *testing.T) {
+	mount := t.TempDir()
+	writeConfigMap(t, mount, "2021_01_01", map[string]string{
+		"db.host":       "db.internal\n",
+		"db.port":       "5432",
+		"settings.json": `{"feature": {"enabled": true}}`,
+	})
+
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                app:
+                    name: foo
+                    db:
+                        host: localhost
+                        user: admin
+            `),
+		},
+	}
+
+	source := configo.KeyPerFileSource(
+		os.DirFS(mount),
+		"app",
+		configo.WithKeyFormat("settings.json", "json"),
+	)
+
+	config, err := configo.NewConfig(dir, configo.WithKeyPerFile(source))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, "foo", config.MustGetString("app.name"))
+	assert.Equal(t, "db.internal", config.MustGetString("app.db.host"))
+	assert.Equal(t, 5432, config.MustGetInt("app.db.port"))
+	assert.Equal(t, "admin", config.MustGetString("app.db.user"))
+	assert.Equal(t, true, config.MustGetBool("app.settings.feature.enabled"))
+
+	pos, _ := config.Position("app.db.host")
+	assert.Equal(t, "db.host", pos.File)
+
+	writeConfigMap(t, mount, "2021_01_02", map[string]string{
+		"db.host":       "db2.internal\n",
+		"db.port":       "5433",
+		"settings.json": `{"feature": {"enabled": false}}`,
+	})
+
+	err = config.Reload()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, "db2.internal", config.MustGetString("app.db.host"))
+	assert.Equal(t, 5433, config.MustGetInt("app.db.port"))
+	assert.Equal(t, false, config.MustGetBool("app.settings.feature.enabled"))
+}
+
+func TestReloadKeepsPreviousOnError(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                p1: foo
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	dir["default.yml"] = &fstest.MapFile{Data: []byte("in valid\n; hhvc: foo")}
+
+	err = config.Reload()
+	assert.NotNil(t, err)
+	assert.Equal(t, "foo", config.MustGetString("p1"))
+}
+
+func TestKeyPerFileInvalidPollInterval(t *testing.T) {
+	dir := fstest.MapFS{"db.host": {Data: []byte("db.internal")}}
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		source := configo.KeyPerFileSource(dir, "", configo.WithKeyPerFilePollInterval(interval))
+
+		err := source.Watch(context.Background(), func() {})
+		assert.EqualError(t, err, fmt.Sprintf("invalid poll interval %s, it must be positive", interval))
+	}
+}
@@ -0,0 +1,184 @@
+package configo
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Has reports whether the path exists, including keys set to null
+func (c *Config) Has(path string) bool {
+	p, err := compilePath(path)
+	if err != nil {
+		return false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, found := c.index[p.canonical]
+	return found
+}
+
+// Keys returns the sorted keys of the map at the given path, or the indexes of a slice.
+// An empty path returns the top-level keys, nil is returned for other values and missing paths
+func (c *Config) Keys(path string) []string {
+	p, err := compilePath(path)
+	if err != nil {
+		return nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	value := c.index[p.canonical]
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return keys
+	case []interface{}:
+		keys := make([]string, len(v))
+		for i := range v {
+			keys[i] = strconv.Itoa(i)
+		}
+		return keys
+	}
+
+	return nil
+}
+
+// AllKeys returns the sorted paths of every leaf. Slices and empty maps are leaves
+func (c *Config) AllKeys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var keys []string
+
+	var walk func(m map[string]interface{}, path string)
+	walk = func(m map[string]interface{}, path string) {
+		for key, value := range m {
+			keyPath := joinKeyPath(path, key)
+			if child, ok := value.(map[string]interface{}); ok && len(child) > 0 {
+				walk(child, keyPath)
+				continue
+			}
+			keys = append(keys, keyPath)
+		}
+	}
+	walk(c.store, "")
+
+	sort.Strings(keys)
+	return keys
+}
+
+// AllSettings returns a deep copy of the loaded configurations.
+// Secrets are not masked, use `Redacted` for values which are displayed or logged
+func (c *Config) AllSettings() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	settings, _ := deepCopy(c.store).(map[string]interface{})
+	return settings
+}
+
+// Sub returns a Config holding a copy of the map at the given path, along with the
+// positions, origins and secrets of the paths under it. The returned Config is detached,
+// changes to c are not reflected in it and it cannot be initialized
+func (c *Config) Sub(path string) (*Config, error) {
+	p, err := compilePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.store == nil {
+		return nil, ErrNotInitialized
+	}
+
+	value, found := c.index[p.canonical]
+	if !found {
+		return nil, fmt.Errorf("unknown key %s", path)
+	}
+
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s is not a map", path)
+	}
+
+	prefix := p.canonical
+	rebase := func(path string) (string, bool) {
+		if prefix == "" {
+			return path, true
+		}
+		if strings.HasPrefix(path, prefix+".") {
+			return path[len(prefix)+1:], true
+		}
+		return "", false
+	}
+
+	store := deepCopy(m).(map[string]interface{})
+	s := snapshot{
+		store:     store,
+		index:     buildIndex(store),
+		positions: Positions{},
+		origins:   map[string][]Position{},
+		secrets:   map[string]struct{}{},
+		files:     append([]LoadedFile(nil), c.files...),
+	}
+
+	for path, pos := range c.positions {
+		if rebased, ok := rebase(path); ok {
+			s.positions[rebased] = pos
+		}
+	}
+
+	for path, origins := range c.origins {
+		if rebased, ok := rebase(path); ok {
+			s.origins[rebased] = append([]Position(nil), origins...)
+		}
+	}
+
+	for path := range c.secrets {
+		if rebased, ok := rebase(path); ok {
+			s.secrets[rebased] = struct{}{}
+		}
+	}
+
+	if prefix != "" && c.isSecret(prefix) {
+		for key := range m {
+			s.secrets[quoteKey(key)] = struct{}{}
+		}
+	}
+
+	timeFormats := map[string]*timeFormat{}
+	if len(c.timeFormats) > 0 {
+		base := c.timeFormat(prefix)
+		timeFormats[""] = &base
+
+		for path, format := range c.timeFormats {
+			if rebased, ok := rebase(path); ok {
+				timeFormats[rebased] = format
+			}
+		}
+	}
+
+	return &Config{
+		environment:     c.environment,
+		lookupEnv:       c.lookupEnv,
+		resolveHostname: c.resolveHostname,
+		secretPaths:     map[string]struct{}{},
+		decryptionKeys:  map[string]func() ([]byte, error){},
+		resolvers:       map[string]Resolver{},
+		timeFormats:     timeFormats,
+		logger:          c.logger,
+		snapshot:        s,
+	}, nil
+}
@@ -0,0 +1,183 @@
+package configo_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/affanshahid/configo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHas(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                db:
+                    host: localhost
+                    replica: null
+                servers: [a, b]
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.True(t, config.Has("db"))
+	assert.True(t, config.Has("db.host"))
+	assert.True(t, config.Has("$.db.host"))
+	assert.True(t, config.Has("db.replica"))
+	assert.True(t, config.Has("servers.1"))
+	assert.False(t, config.Has("servers.2"))
+	assert.False(t, config.Has("db.user"))
+	assert.False(t, config.Has("db.host.name"))
+	assert.False(t, config.Has("missing"))
+}
+
+func TestKeys(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                db:
+                    host: localhost
+                    port: 5432
+                servers: [a, b]
+                empty: {}
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, []string{"db", "empty", "servers"}, config.Keys(""))
+	assert.Equal(t, []string{"host", "port"}, config.Keys("db"))
+	assert.Equal(t, []string{"0", "1"}, config.Keys("servers"))
+	assert.Empty(t, config.Keys("empty"))
+	assert.Nil(t, config.Keys("db.host"))
+	assert.Nil(t, config.Keys("missing"))
+}
+
+func TestAllKeys(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                db:
+                    host: localhost
+                    replica: null
+                servers: [a, b]
+                empty: {}
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, []string{"db.host", "db.replica", "empty", "servers"}, config.AllKeys())
+}
+
+func TestAllSettings(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                db:
+                    host: localhost
+                    password: hunter2
+                servers: [a, b]
+                secret: [db.password]
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	settings := config.AllSettings()
+	assert.Equal(t, "hunter2", settings["db"].(map[string]interface{})["password"])
+
+	settings["db"].(map[string]interface{})["host"] = "changed"
+	settings["servers"].([]interface{})[0] = "changed"
+
+	assert.Equal(t, "localhost", config.MustGetString("db.host"))
+	assert.Equal(t, []string{"a", "b"}, config.MustGetStringSlice("servers"))
+}
+
+func TestGetReturnsCopies(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                db:
+                    host: localhost
+                    port: 5432
+                servers: [a, b]
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	db, err := config.GetStringMap("db")
+	assert.Nilf(t, err, "err should be nil")
+	db["host"] = "changed"
+
+	root, err := config.Get("")
+	assert.Nilf(t, err, "err should be nil")
+	root.(map[string]interface{})["db"].(map[string]interface{})["port"] = 0
+
+	servers, err := config.Get("servers")
+	assert.Nilf(t, err, "err should be nil")
+	servers.([]interface{})[0] = "changed"
+
+	assert.Equal(t, "localhost", config.MustGetString("db.host"))
+	assert.Equal(t, 5432, config.MustGetInt("db.port"))
+	assert.Equal(t, []string{"a", "b"}, config.MustGetStringSlice("servers"))
+	assert.Equal(t, "localhost", config.AllSettings()["db"].(map[string]interface{})["host"])
+}
+
+func TestGlobalKeys(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                db:
+                    host: localhost
+                    port: 5432
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	previous := configo.Global()
+	defer configo.SetGlobal(previous)
+
+	configo.SetGlobal(nil)
+	assert.False(t, configo.Has("db.host"))
+	assert.Nil(t, configo.AllKeys())
+
+	configo.SetGlobal(config)
+	assert.True(t, configo.Has("db.host"))
+	assert.Equal(t, []string{"host", "port"}, configo.Keys("db"))
+	assert.Equal(t, []string{"db.host", "db.port"}, configo.AllKeys())
+	assert.Contains(t, configo.AllSettings(), "db")
+}
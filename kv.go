@@ -0,0 +1,174 @@
+package configo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// kvPair is a single entry of a key-value store
+type kvPair struct {
+	key   string
+	value []byte
+}
+
+// kvClient holds the connection settings shared by key-value sources
+type kvClient struct {
+	address string
+	token   string
+	client  *http.Client
+
+	// addressEnv and tokenEnv are read when the address or token are not set,
+	// defaultAddress is used when neither are
+	addressEnv, tokenEnv string
+	defaultAddress       string
+}
+
+// KVOption is a functional option to configure a Consul or etcd source
+type KVOption func(*kvClient)
+
+// WithKVAddress sets the address of the key-value store, i.e `http://127.0.0.1:8500`
+func WithKVAddress(address string) KVOption {
+	return func(k *kvClient) {
+		k.address = strings.TrimSuffix(address, "/")
+	}
+}
+
+// WithKVToken sets the token used to authenticate with the key-value store
+func WithKVToken(token string) KVOption {
+	return func(k *kvClient) {
+		k.token = token
+	}
+}
+
+// WithKVClient sets the client used for requests
+func WithKVClient(client *http.Client) KVOption {
+	return func(k *kvClient) {
+		k.client = client
+	}
+}
+
+func newKVClient(defaultAddress, addressEnv, tokenEnv string, opts []KVOption) kvClient {
+	k := kvClient{
+		client:         &http.Client{},
+		addressEnv:     addressEnv,
+		tokenEnv:       tokenEnv,
+		defaultAddress: defaultAddress,
+	}
+
+	for _, opt := range opts {
+		opt(&k)
+	}
+
+	return k
+}
+
+// connection returns the address and token of the store, the environment variables
+// are read through `LookupEnv` so the lookup of the Config applies
+func (k *kvClient) connection(ctx context.Context) (address, token string) {
+	address, token = k.address, k.token
+
+	if address == "" {
+		if k.addressEnv != "" {
+			value, _ := LookupEnv(ctx, k.addressEnv)
+			address = strings.Split(value, ",")[0]
+		}
+		if address == "" {
+			address = k.defaultAddress
+		}
+		if !strings.Contains(address, "://") {
+			address = "http://" + address
+		}
+	}
+
+	if token == "" && k.tokenEnv != "" {
+		token, _ = LookupEnv(ctx, k.tokenEnv)
+	}
+
+	return address, token
+}
+
+// kvDir returns the prefix as a folder so that only its children match, i.e `config/app/`
+// does not match `config/application`
+func kvDir(prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+
+	return strings.TrimSuffix(prefix, "/") + "/"
+}
+
+// kvToMap converts the entries under prefix into a nested map.
+// Slash separated keys become nested keys, values of keys with the extension of a
+// registered format are parsed with that format and JSON objects and arrays are decoded.
+// Positions point at `<scheme>://<key>` of the entry defining each path
+func kvToMap(scheme, prefix string, pairs []kvPair) (map[string]interface{}, Positions, error) {
+	data := map[string]interface{}{}
+	positions := Positions{}
+
+	dir := kvDir(prefix)
+
+	for _, pair := range pairs {
+		if !strings.HasPrefix(pair.key, dir) {
+			// a sibling such as `config/application` of the prefix `config/app`
+			continue
+		}
+
+		rel := strings.Trim(strings.TrimPrefix(pair.key, dir), "/")
+		if rel == "" || strings.HasSuffix(pair.key, "/") {
+			// folders
+			continue
+		}
+
+		keys := strings.Split(rel, "/")
+		var value interface{} = string(pair.value)
+		pos := Position{File: scheme + "://" + strings.TrimPrefix(pair.key, "/")}
+
+		last := keys[len(keys)-1]
+		ext := strings.ToLower(path.Ext(last))
+		if provider, found := defaultProviders[ext]; found {
+			parsed, filePositions, err := parseWith(provider, pair.value)
+			if err != nil {
+				return nil, nil, withFile(err, pos.File)
+			}
+
+			keys[len(keys)-1] = strings.TrimSuffix(last, path.Ext(last))
+			value = parsed
+
+			keyPath := JoinPath(keys...)
+			for p, filePos := range filePositions {
+				filePos.File = pos.File
+				positions[joinPaths(keyPath, p)] = filePos
+			}
+		} else if trimmed := strings.TrimSpace(string(pair.value)); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			var decoded interface{}
+			if err := json.Unmarshal(pair.value, &decoded); err == nil {
+				value = decoded
+				collectPositions(decoded, JoinPath(keys...), pos, positions)
+			}
+		}
+
+		for i := range keys {
+			positions[JoinPath(keys[:i+1]...)] = pos
+		}
+		setNested(data, keys, value)
+	}
+
+	return data, positions, nil
+}
+
+// sleepContext waits for the duration and reports false if the context was done first
+func sleepContext(done <-chan struct{}, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,363 @@
+package configo_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/affanshahid/configo"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeKV is an in-memory key-value store with a revision counter
+type fakeKV struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	data     map[string]string
+	revision int64
+	// compacted is the oldest revision watches can start from
+	compacted int64
+}
+
+func newFakeKV(data map[string]string) *fakeKV {
+	kv := &fakeKV{data: data, revision: 1}
+	kv.cond = sync.NewCond(&kv.mu)
+	return kv
+}
+
+func (kv *fakeKV) put(key, value string) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	kv.data[key] = value
+	kv.revision++
+	kv.cond.Broadcast()
+}
+
+// wait blocks until cond holds or the request is cancelled, kv.mu must be held
+func (kv *fakeKV) wait(r *http.Request, cond func() bool) bool {
+	stop := context.AfterFunc(r.Context(), func() {
+		kv.mu.Lock()
+		defer kv.mu.Unlock()
+		kv.cond.Broadcast()
+	})
+	defer stop()
+
+	for !cond() {
+		if r.Context().Err() != nil {
+			return false
+		}
+		kv.cond.Wait()
+	}
+
+	return true
+}
+
+func (kv *fakeKV) list(prefix string) []string {
+	var keys []string
+	for key := range kv.data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// consulHandler fakes the Consul KV HTTP API including blocking queries
+func (kv *fakeKV) consulHandler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token", r.Header.Get("X-Consul-Token"))
+		prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+
+		kv.mu.Lock()
+		defer kv.mu.Unlock()
+
+		if index, err := strconv.ParseInt(r.URL.Query().Get("index"), 10, 64); err == nil {
+			if !kv.wait(r, func() bool { return kv.revision > index }) {
+				return
+			}
+		}
+
+		type pair struct {
+			Key   string
+			Value []byte
+		}
+
+		var pairs []pair
+		for _, key := range kv.list(prefix) {
+			pairs = append(pairs, pair{key, []byte(kv.data[key])})
+		}
+
+		w.Header().Set("X-Consul-Index", strconv.FormatInt(kv.revision, 10))
+		if len(pairs) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		json.NewEncoder(w).Encode(pairs)
+	})
+}
+
+// etcdHandler fakes the range and watch endpoints of the etcd v3 JSON gateway
+func (kv *fakeKV) etcdHandler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/kv/range":
+			var req struct {
+				Key      []byte `json:"key"`
+				RangeEnd []byte `json:"range_end"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+
+			kv.mu.Lock()
+			defer kv.mu.Unlock()
+
+			type keyValue struct {
+				Key   []byte `json:"key"`
+				Value []byte `json:"value"`
+			}
+
+			var kvs []keyValue
+			for _, key := range kv.list(string(req.Key)) {
+				if bytes.Compare([]byte(key), req.RangeEnd) < 0 {
+					kvs = append(kvs, keyValue{[]byte(key), []byte(kv.data[key])})
+				}
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"header": map[string]string{"revision": strconv.FormatInt(kv.revision, 10)},
+				"kvs":    kvs,
+			})
+		case "/v3/watch":
+			var req struct {
+				CreateRequest struct {
+					StartRevision string `json:"start_revision"`
+				} `json:"create_request"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			start, _ := strconv.ParseInt(req.CreateRequest.StartRevision, 10, 64)
+
+			flusher := w.(http.Flusher)
+			enc := json.NewEncoder(w)
+
+			kv.mu.Lock()
+			if start < kv.compacted {
+				enc.Encode(map[string]interface{}{
+					"result": map[string]interface{}{
+						"header":           map[string]string{"revision": strconv.FormatInt(kv.revision, 10)},
+						"created":          true,
+						"canceled":         true,
+						"compact_revision": strconv.FormatInt(kv.compacted, 10),
+					},
+				})
+				kv.mu.Unlock()
+				return
+			}
+
+			enc.Encode(map[string]interface{}{
+				"result": map[string]interface{}{
+					"header":  map[string]string{"revision": strconv.FormatInt(kv.revision, 10)},
+					"created": true,
+				},
+			})
+			flusher.Flush()
+
+			if !kv.wait(r, func() bool { return kv.revision >= start }) {
+				kv.mu.Unlock()
+				return
+			}
+
+			enc.Encode(map[string]interface{}{
+				"result": map[string]interface{}{
+					"header": map[string]string{"revision": strconv.FormatInt(kv.revision, 10)},
+					"events": []map[string]string{{"type": "PUT"}},
+				},
+			})
+			flusher.Flush()
+			kv.mu.Unlock()
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func TestConsulSource(t *testing.T) {
+	kv := newFakeKV(map[string]string{
+		"config/app/db/host":      "consul.internal",
+		"config/app/db/port":      "5433",
+		"config/app/":             "",
+		"config/app/features":     `{"beta": true}`,
+		"config/app/limits.yaml":  "requests: 100\n",
+		"config/other/db/host":    "other",
+		"config/application/name": "other",
+	})
+
+	server := httptest.NewServer(kv.consulHandler(t))
+	defer server.Close()
+
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                db:
+                    host: localhost
+                    user: admin
+            `),
+		},
+	}
+
+	source := configo.ConsulSource("config/app", configo.WithKVAddress(server.URL), configo.WithKVToken("token"))
+
+	config, err := configo.NewConfig(dir, configo.WithSource(source, configo.AfterFiles))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, "consul.internal", config.MustGetString("db.host"))
+	assert.Equal(t, 5433, config.MustGetInt("db.port"))
+	assert.Equal(t, "admin", config.MustGetString("db.user"))
+	assert.Equal(t, true, config.MustGetBool("features.beta"))
+	assert.Equal(t, 100, config.MustGetInt("limits.requests"))
+	assert.Equal(t, []string{"db", "features", "limits"}, config.Keys(""))
+
+	pos, _ := config.Position("db.host")
+	assert.Equal(t, "consul://config/app/db/host", pos.File)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan error, 1)
+	go config.Watch(ctx, func(err error) {
+		reloaded <- err
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	kv.put("config/app/db/host", "consul2.internal")
+
+	select {
+	case err := <-reloaded:
+		assert.Nilf(t, err, "err should be nil")
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+
+	assert.Equal(t, "consul2.internal", config.MustGetString("db.host"))
+}
+
+func TestKVEnvLookup(t *testing.T) {
+	kv := newFakeKV(map[string]string{
+		"config/app/db/host": "consul.internal",
+	})
+
+	server := httptest.NewServer(kv.consulHandler(t))
+	defer server.Close()
+
+	env := map[string]string{"CONSUL_HTTP_ADDR": server.URL, "CONSUL_HTTP_TOKEN": "token"}
+	lookup := func(name string) (string, bool) {
+		value, found := env[name]
+		return value, found
+	}
+
+	source := configo.ConsulSource("config/app")
+
+	config, err := configo.NewConfig(fstest.MapFS{}, configo.WithSource(source, configo.AfterFiles), configo.WithEnvLookup(lookup))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, "consul.internal", config.MustGetString("db.host"))
+}
+
+func TestEtcdSource(t *testing.T) {
+	kv := newFakeKV(map[string]string{
+		"/config/app/db/host":      "etcd.internal",
+		"/config/app/db/port":      "2379",
+		"/config/app/limits.json":  `{"requests": 100}`,
+		"/config/other/db/host":    "other",
+		"/config/application/name": "other",
+	})
+
+	server := httptest.NewServer(kv.etcdHandler(t))
+	defer server.Close()
+
+	source := configo.EtcdSource("/config/app", configo.WithKVAddress(server.URL))
+
+	config, err := configo.NewConfig(fstest.MapFS{}, configo.WithSource(source, configo.AfterFiles))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, "etcd.internal", config.MustGetString("db.host"))
+	assert.Equal(t, 2379, config.MustGetInt("db.port"))
+	assert.Equal(t, 100, config.MustGetInt("limits.requests"))
+	assert.Equal(t, []string{"db", "limits"}, config.Keys(""))
+
+	pos, _ := config.Position("limits.requests")
+	assert.Equal(t, "etcd://config/app/limits.json", pos.File)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan error, 1)
+	go config.Watch(ctx, func(err error) {
+		reloaded <- err
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	kv.put("/config/app/db/host", "etcd2.internal")
+
+	select {
+	case err := <-reloaded:
+		assert.Nilf(t, err, "err should be nil")
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+
+	assert.Equal(t, "etcd2.internal", config.MustGetString("db.host"))
+}
+
+func TestEtcdWatchCompacted(t *testing.T) {
+	kv := newFakeKV(map[string]string{"/config/app/db/host": "etcd.internal"})
+	// the revision after the current one is already compacted
+	kv.compacted = 3
+
+	server := httptest.NewServer(kv.etcdHandler(t))
+	defer server.Close()
+
+	source := configo.EtcdSource("/config/app", configo.WithKVAddress(server.URL))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := make(chan struct{}, 10)
+	go source.Watch(ctx, func() {
+		changes <- struct{}{}
+	})
+
+	// events may have been missed before the compacted revision
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("compaction was not reported as a change")
+	}
+
+	kv.put("/config/app/db/host", "etcd2.internal")
+	kv.put("/config/app/db/host", "etcd3.internal")
+
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not resume from the compacted revision")
+	}
+}
@@ -0,0 +1,373 @@
+package configo
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/imdario/mergo"
+)
+
+// Lint rules reported in `LintIssue.Rule`
+const (
+	// LintOrphanKey is a key of an overlay file which does not exist in `default.EXT`
+	LintOrphanKey = "orphan-key"
+	// LintEnvMapping is a mapping of `env.EXT` to a path which does not exist in `default.EXT`
+	LintEnvMapping = "env-mapping"
+	// LintUnmatchedFile is a file which matches no template for the known deployments and hostnames
+	LintUnmatchedFile = "unmatched-file"
+	// LintDuplicateBasename is a file which shares its basename with a file of another extension
+	LintDuplicateBasename = "duplicate-basename"
+	// LintDeprecatedKey is a key registered with `WithAlias` or `WithDeprecated`
+	LintDeprecatedKey = "deprecated-key"
+	// LintNewerVersion is a file with a version later than the latest version migrated to by `WithMigration`
+	LintNewerVersion = "newer-version"
+)
+
+// LintIssue is a problem found by `Lint`
+type LintIssue struct {
+	Position
+	Rule    string
+	Message string
+}
+
+// String formats the issue as `file:line:column: message (rule)`
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s: %s (%s)", i.Position, i.Message, i.Rule)
+}
+
+type linter struct {
+	deployments []string
+	hostnames   []string
+}
+
+// LintOption is a functional option to configure `Lint`
+type LintOption func(*linter)
+
+// WithLintDeployments sets the known deployments, files are matched against the
+// templates of every one of them. Defaults to the configured deployment
+func WithLintDeployments(deployments ...string) LintOption {
+	return func(l *linter) {
+		l.deployments = append(l.deployments, deployments...)
+	}
+}
+
+// WithLintHostnames sets the known full hostnames, files are matched against the
+// templates of every one of them. Defaults to the configured hostname
+func WithLintHostnames(hostnames ...string) LintOption {
+	return func(l *linter) {
+		l.hostnames = append(l.hostnames, hostnames...)
+	}
+}
+
+// Lint checks every configuration file of the directory, not only the ones
+// loaded for the current environment, for mistakes which would otherwise be silently ignored:
+//
+//	keys of overlay files which do not exist in `default.EXT`, i.e misspelled keys
+//	mappings of `env.EXT` to paths which do not exist in `default.EXT`
+//	files which match no template for the known deployments and hostnames
+//	files which share their basename with a file of another extension
+//	keys registered with `WithAlias` or `WithDeprecated`
+//	files with a version later than the latest version migrated to by `WithMigration`
+//
+// Lint does not require `Initialize` to be called. Issues are sorted by position
+func (c *Config) Lint(opts ...LintOption) ([]LintIssue, error) {
+	l := &linter{}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	env, err := c.resolveEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.validateMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.validateDeprecations()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(l.deployments) == 0 {
+		l.deployments = []string{env.deployment}
+	}
+
+	if len(l.hostnames) == 0 {
+		l.hostnames = []string{env.fullHostname}
+	}
+
+	files, err := fs.ReadDir(c.dir, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []LintIssue
+	basenames := map[string]string{}
+	templates := l.templates()
+
+	type parsedFile struct {
+		name      string
+		data      map[string]interface{}
+		positions Positions
+	}
+
+	var defaults, overlays []parsedFile
+	var envFile *parsedFile
+
+	for _, file := range files {
+		name := file.Name()
+		ext := filepath.Ext(name)
+		if _, supported := defaultProviders[strings.ToLower(ext)]; file.IsDir() || !supported {
+			continue
+		}
+
+		basename := strings.TrimSuffix(name, ext)
+		if other, found := basenames[basename]; found {
+			issues = append(issues, LintIssue{
+				Position: Position{File: name},
+				Rule:     LintDuplicateBasename,
+				Message:  fmt.Sprintf("shares the basename %q with %s", basename, other),
+			})
+		} else {
+			basenames[basename] = name
+		}
+
+		if basename != envFileName && !matchesAny(templates, basename) {
+			issues = append(issues, LintIssue{
+				Position: Position{File: name},
+				Rule:     LintUnmatchedFile,
+				Message:  "matches no template for the known deployments and hostnames",
+			})
+		}
+
+		data, positions, _, err := c.readFile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := isSecretDirective(data); ok {
+			delete(data, secretDirective)
+		}
+
+		if basename != envFileName {
+			err = c.migrateFile(name, data, positions)
+
+			var perr *ParseError
+			if errors.Is(err, errNewerVersion) && errors.As(err, &perr) {
+				// the layout of the file is unknown, its keys are not checked
+				issues = append(issues, LintIssue{Position: perr.Position, Rule: LintNewerVersion, Message: perr.Err.Error()})
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// aliases are migrated so renamed keys are not reported as orphans as well
+		err = c.migrateDeprecated(data, positions, func(d Deprecation) error {
+			pos := lintPosition(name, positions, d.Path)
+			d.Position = Position{}
+			issues = append(issues, LintIssue{Position: pos, Rule: LintDeprecatedKey, Message: d.Error()})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		parsed := parsedFile{name, data, positions}
+
+		switch basename {
+		case "default":
+			defaults = append(defaults, parsed)
+		case envFileName:
+			envFile = &parsed
+		default:
+			overlays = append(overlays, parsed)
+		}
+	}
+
+	if len(defaults) > 0 {
+		base := map[string]interface{}{}
+		for _, file := range defaults {
+			err := mergo.Merge(&base, file.data, mergo.WithOverride)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, file := range overlays {
+			orphanKeys(file.data, base, "", func(path string) {
+				issues = append(issues, LintIssue{
+					Position: lintPosition(file.name, file.positions, path),
+					Rule:     LintOrphanKey,
+					Message:  fmt.Sprintf("%s does not exist in the default configurations", path),
+				})
+			})
+		}
+
+		if envFile != nil {
+			missingMappings(envFile.data, base, "", func(path string) {
+				issues = append(issues, LintIssue{
+					Position: lintPosition(envFile.name, envFile.positions, path),
+					Rule:     LintEnvMapping,
+					Message:  fmt.Sprintf("%s maps to a path which does not exist in the default configurations", path),
+				})
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i].Position, issues[j].Position
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+
+	return issues, nil
+}
+
+// templates compiles the ordered templates for the known deployments and hostnames,
+// instances can be anything
+func (l *linter) templates() []*regexp.Regexp {
+	var shortHostnames []string
+	for _, hostname := range l.hostnames {
+		shortHostnames = append(shortHostnames, strings.Split(hostname, ".")[0])
+	}
+
+	replacements := map[string]string{
+		"{deployment}":    alternation(l.deployments),
+		"{instance}":      ".+",
+		"{shortHostname}": alternation(shortHostnames),
+		"{fullHostname}":  alternation(l.hostnames),
+	}
+
+	placeholder := regexp.MustCompile(`\{\w+\}`)
+
+	templates := make([]*regexp.Regexp, 0, len(orderedTemplates))
+	for _, tmpl := range orderedTemplates {
+		var b strings.Builder
+		b.WriteString("^")
+
+		last := 0
+		for _, loc := range placeholder.FindAllStringIndex(tmpl, -1) {
+			b.WriteString(regexp.QuoteMeta(tmpl[last:loc[0]]))
+			b.WriteString(replacements[tmpl[loc[0]:loc[1]]])
+			last = loc[1]
+		}
+
+		b.WriteString(regexp.QuoteMeta(tmpl[last:]))
+		b.WriteString("$")
+
+		templates = append(templates, regexp.MustCompile(b.String()))
+	}
+
+	return templates
+}
+
+// alternation matches any of the given values, or nothing if there are none
+func alternation(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			quoted = append(quoted, regexp.QuoteMeta(value))
+		}
+	}
+
+	if len(quoted) == 0 {
+		return `[^\s\S]`
+	}
+
+	return "(?:" + strings.Join(quoted, "|") + ")"
+}
+
+func matchesAny(templates []*regexp.Regexp, basename string) bool {
+	for _, tmpl := range templates {
+		if tmpl.MatchString(basename) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// orphanKeys calls report with the topmost paths of overlay which do not exist in base.
+// Only maps are merged key by key, other values replace each other and are not descended into
+func orphanKeys(overlay, base interface{}, path string, report func(path string)) {
+	o, ok := overlay.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	b, ok := base.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for _, key := range sortedKeys(o) {
+		keyPath := joinKeyPath(path, key)
+		if val, found := b[key]; found {
+			orphanKeys(o[key], val, keyPath, report)
+		} else {
+			report(keyPath)
+		}
+	}
+}
+
+// missingMappings calls report with the paths of env mappings which do not exist in base,
+// numeric keys address items of slices
+func missingMappings(mappings, base interface{}, path string, report func(path string)) {
+	m, ok := mappings.(map[string]interface{})
+	if !ok {
+		if base == nil {
+			report(path)
+		}
+		return
+	}
+
+	for _, key := range sortedKeys(m) {
+		var val interface{}
+
+		switch b := base.(type) {
+		case map[string]interface{}:
+			val = b[key]
+		case []interface{}:
+			if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(b) {
+				val = b[i]
+			}
+		}
+
+		missingMappings(m[key], val, joinKeyPath(path, key), report)
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// lintPosition returns the position of the path, falling back to the file
+func lintPosition(file string, positions Positions, path string) Position {
+	if pos, found := positions[path]; found {
+		return pos
+	}
+
+	return Position{File: file}
+}
@@ -0,0 +1,114 @@
+package configo_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/affanshahid/configo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                database:
+                    host: localhost
+                    port: 5432
+                servers:
+                    - name: a
+                secret:
+                    - database.host
+            `),
+		},
+		"production.yml": {
+			Data: []byte(`
+                dtabase:
+                    host: prod.internal
+                database:
+                    prot: 5433
+                servers:
+                    - name: b
+                      weight: 2
+            `),
+		},
+		"production-2.yml": {
+			Data: []byte(`
+                database:
+                    host: prod2.internal
+            `),
+		},
+		"prodution.yml": {
+			Data: []byte(`
+                database:
+                    host: prod.internal
+            `),
+		},
+		"web1.json": {
+			Data: []byte(`{"database": {"port": 1}}`),
+		},
+		"env.yml": {
+			Data: []byte(`
+                database:
+                    host: DB_HOST
+                    pasword: DB_PASSWORD
+                servers:
+                    "0":
+                        name: SERVER_NAME
+                    "1":
+                        name: SERVER2_NAME
+            `),
+		},
+		"local.yml": {
+			Data: []byte(`
+                database:
+                    port: 1
+            `),
+		},
+		"local.json": {
+			Data: []byte(`{}`),
+		},
+		"README.md": {
+			Data: []byte(`# config`),
+		},
+	}
+
+	config, err := configo.NewConfig(dir, configo.WithHostname("web1.example.com"))
+	assert.Nilf(t, err, "err should be nil")
+
+	issues, err := config.Lint(configo.WithLintDeployments("staging", "production"))
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, []configo.LintIssue{
+		{
+			Position: configo.Position{File: "env.yml", Line: 4, Column: 21},
+			Rule:     configo.LintEnvMapping,
+			Message:  "database.pasword maps to a path which does not exist in the default configurations",
+		},
+		{
+			Position: configo.Position{File: "env.yml", Line: 9, Column: 25},
+			Rule:     configo.LintEnvMapping,
+			Message:  "servers.1.name maps to a path which does not exist in the default configurations",
+		},
+		{
+			Position: configo.Position{File: "local.yml"},
+			Rule:     configo.LintDuplicateBasename,
+			Message:  `shares the basename "local" with local.json`,
+		},
+		{
+			Position: configo.Position{File: "production.yml", Line: 2, Column: 17},
+			Rule:     configo.LintOrphanKey,
+			Message:  "dtabase does not exist in the default configurations",
+		},
+		{
+			Position: configo.Position{File: "production.yml", Line: 5, Column: 21},
+			Rule:     configo.LintOrphanKey,
+			Message:  "database.prot does not exist in the default configurations",
+		},
+		{
+			Position: configo.Position{File: "prodution.yml"},
+			Rule:     configo.LintUnmatchedFile,
+			Message:  "matches no template for the known deployments and hostnames",
+		},
+	}, issues)
+}
@@ -0,0 +1,62 @@
+package configo
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// WithLogger logs the steps of `Initialize`: the templates tried, the files loaded and skipped
+// and the environment variables applied. Values are never logged.
+// A warning is logged when no file matches the deployment, which usually means
+// the deployment is misspelled and the defaults are used instead. A nil logger disables logging
+func WithLogger(logger *slog.Logger) ConfigOption {
+	return func(c *Config) {
+		if logger == nil {
+			logger = slog.New(discardHandler{})
+		}
+		c.logger = logger
+	}
+}
+
+// discardHandler is the handler of the default logger, it drops every record
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// logDiagnostics logs the files of the directory which were not loaded and warns
+// if none of the loaded files matched the deployment
+func (c *Config) logDiagnostics(s *snapshot, fileMap map[string][]fs.DirEntry, env environment) {
+	loaded := map[string]struct{}{}
+	deploymentMatched := false
+
+	for _, file := range s.files {
+		loaded[file.Name] = struct{}{}
+		if strings.Contains(file.Template, "{deployment}") {
+			deploymentMatched = true
+		}
+	}
+
+	basenames := make([]string, 0, len(fileMap))
+	for basename := range fileMap {
+		basenames = append(basenames, basename)
+	}
+	sort.Strings(basenames)
+
+	for _, basename := range basenames {
+		for _, entry := range fileMap[basename] {
+			if _, found := loaded[entry.Name()]; !found {
+				c.logger.Debug("skipped file", "file", entry.Name(), "reason", "matches no template for the environment")
+			}
+		}
+	}
+
+	if !deploymentMatched {
+		c.logger.Warn("no configuration file matches the deployment", "deployment", env.deployment)
+	}
+}
@@ -0,0 +1,79 @@
+package configo_test
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/affanshahid/configo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLogger(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                p1: foo
+                p2: bar
+            `),
+		},
+		"prod.yml": {
+			Data: []byte(`
+                p1: baz
+            `),
+		},
+		"env.yml": {
+			Data: []byte(`
+                p1: LOGGER_P1_ENV
+                p2: LOGGER_P2_ENV
+            `),
+		},
+		"notes.txt": {
+			Data: []byte(`not a config file`),
+		},
+	}
+
+	t.Setenv("LOGGER_P1_ENV", "secret value")
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	config, err := configo.NewConfig(dir, configo.WithDeployment("production"), configo.WithLogger(logger))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	logs := buf.String()
+	assert.Contains(t, logs, `level=INFO msg="loaded file" file=default.yml template=default`)
+	assert.Contains(t, logs, `level=DEBUG msg="no file for template" template={deployment} basename=production`)
+	assert.Contains(t, logs, `level=DEBUG msg="skipped file" file=notes.txt reason="unsupported extension"`)
+	assert.Contains(t, logs, `level=DEBUG msg="skipped file" file=prod.yml reason="matches no template for the environment"`)
+	assert.Contains(t, logs, `level=INFO msg="applied environment variable" variable=LOGGER_P1_ENV path=p1`)
+	assert.Contains(t, logs, `level=DEBUG msg="environment variable not set" variable=LOGGER_P2_ENV path=p2`)
+	assert.Contains(t, logs, `level=WARN msg="no configuration file matches the deployment" deployment=production`)
+	assert.NotContains(t, logs, "secret value")
+
+	buf.Reset()
+
+	config, err = configo.NewConfig(dir, configo.WithDeployment("prod"), configo.WithLogger(logger))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+	assert.NotContains(t, buf.String(), "level=WARN")
+}
+
+func TestWithNilLogger(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {Data: []byte("p1: foo\n")},
+	}
+
+	config, err := configo.NewConfig(dir, configo.WithLogger(nil))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, "foo", config.MustGetString("p1"))
+}
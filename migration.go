@@ -0,0 +1,279 @@
+package configo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// versionDirective is the top-level key holding the version of the layout of a file
+const versionDirective = "version"
+
+// migration is a step registered with `WithMigration`
+type migration struct {
+	from, to int
+	migrate  func(data map[string]interface{}) error
+}
+
+// WithMigration registers a step migrating files from one version of their layout to a later one.
+// Once migrations are registered the top-level `version` key of every configuration file is read
+// as the version of the file, files without it are at the lowest version migrated from.
+// `Initialize` applies the steps to each file, in order, before it is merged
+// and fails on files with a version later than the latest version migrated to:
+//
+//	configo.WithMigration(1, 2, func(data map[string]interface{}) error {
+//		db, _ := data["db"].(map[string]interface{})
+//		if addr, found := db["addr"]; found {
+//			db["host"] = addr
+//			delete(db, "addr")
+//		}
+//		return nil
+//	})
+func WithMigration(from, to int, migrate func(data map[string]interface{}) error) ConfigOption {
+	return func(c *Config) {
+		c.migrations = append(c.migrations, migration{from, to, migrate})
+	}
+}
+
+// validateMigrations checks that every version is migrated from at most once, to a later version
+func (c *Config) validateMigrations() error {
+	from := map[int]struct{}{}
+
+	for _, m := range c.migrations {
+		if m.to <= m.from {
+			return fmt.Errorf("migration from version %d to %d does not move forward", m.from, m.to)
+		}
+		if m.migrate == nil {
+			return fmt.Errorf("migration from version %d to %d has no function", m.from, m.to)
+		}
+		if _, found := from[m.from]; found {
+			return fmt.Errorf("multiple migrations from version %d", m.from)
+		}
+		from[m.from] = struct{}{}
+	}
+
+	return nil
+}
+
+// versions returns the version of files without one and the latest version
+func (c *Config) versions() (oldest, latest int) {
+	oldest, latest = c.migrations[0].from, c.migrations[0].to
+	for _, m := range c.migrations[1:] {
+		if m.from < oldest {
+			oldest = m.from
+		}
+		if m.to > latest {
+			latest = m.to
+		}
+	}
+
+	return oldest, latest
+}
+
+// migrateFile removes the version directive from the data of the file and migrates it to the latest version.
+// Positions of values moved by the migration are moved along, see `movedPositions`,
+// positions of other paths which do not exist after the migration are dropped
+func (c *Config) migrateFile(name string, data map[string]interface{}, positions Positions) error {
+	if len(c.migrations) == 0 {
+		return nil
+	}
+
+	pos, found := positions[versionDirective]
+	if !found {
+		pos = Position{File: name}
+	}
+
+	before := buildIndex(deepCopy(data).(map[string]interface{}))
+
+	version, err := c.migrateData(data)
+	if err != nil {
+		return &ParseError{Position: pos, Err: fmt.Errorf("version %s %w", version, err)}
+	}
+
+	after := buildIndex(data)
+	movedPositions(positions, before, after)
+
+	for path := range positions {
+		if _, found := after[path]; !found {
+			delete(positions, path)
+		}
+	}
+
+	return nil
+}
+
+// movedPositions gives paths added by a migration the positions of a removed path holding the same value,
+// preferring removed paths with the same last key, i.e `db.addr` moved to `database.addr`.
+// before and after are the indexes of the data before and after the migration
+func movedPositions(positions Positions, before, after map[string]interface{}) {
+	var removed []string
+	for path := range positions {
+		if _, found := after[path]; !found && path != "" {
+			removed = append(removed, path)
+		}
+	}
+	sort.Strings(removed)
+
+	added := make([]string, 0, len(after))
+	for path := range after {
+		added = append(added, path)
+	}
+	// parents come first, so the positions of a moved map are moved along with it
+	sort.Strings(added)
+
+	claimed := map[string]struct{}{}
+	for _, path := range added {
+		if _, found := positions[path]; found || path == "" {
+			continue
+		}
+
+		from := ""
+		for _, r := range removed {
+			if _, found := claimed[r]; found || !reflect.DeepEqual(before[r], after[path]) {
+				continue
+			}
+
+			if from == "" {
+				from = r
+			}
+			if lastKey(r) == lastKey(path) {
+				from = r
+				break
+			}
+		}
+
+		if from == "" {
+			continue
+		}
+
+		for p, pos := range positions {
+			if p == from || strings.HasPrefix(p, from+".") {
+				positions[path+p[len(from):]] = pos
+				claimed[p] = struct{}{}
+			}
+		}
+	}
+}
+
+// lastKey returns the last key of a path in the form produced by `JoinPath`
+func lastKey(path string) string {
+	keys, err := SplitPath(path)
+	if err != nil || len(keys) == 0 {
+		return path
+	}
+
+	return keys[len(keys)-1]
+}
+
+// errNewerVersion is returned for files with a version later than the latest version migrated to
+var errNewerVersion = errors.New("the file is newer than this build")
+
+// parseVersion converts the value of the version directive, fractional numbers are rejected
+// instead of being truncated
+func parseVersion(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) || v > math.MaxInt32 || v < math.MinInt32 {
+			return 0, errors.New("not a whole number")
+		}
+		return int(v), nil
+	case float32:
+		return parseVersion(float64(v))
+	}
+
+	return cast.ToIntE(value)
+}
+
+// migrateData removes the version directive from the data and applies the migrations,
+// the returned version describes the version of the data for errors
+func (c *Config) migrateData(data map[string]interface{}) (string, error) {
+	oldest, latest := c.versions()
+
+	version := oldest
+	if value, found := data[versionDirective]; found {
+		v, err := parseVersion(value)
+		if err != nil {
+			return fmt.Sprint(value), errors.New("is not a whole number")
+		}
+		version = v
+	}
+	delete(data, versionDirective)
+
+	if version > latest {
+		return fmt.Sprint(version), fmt.Errorf("is later than the supported version %d, %w", latest, errNewerVersion)
+	}
+
+	migrations := append([]migration(nil), c.migrations...)
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].from < migrations[j].from
+	})
+
+	for version < latest {
+		i := sort.Search(len(migrations), func(i int) bool {
+			return migrations[i].from >= version
+		})
+		if i == len(migrations) || migrations[i].from != version {
+			return fmt.Sprint(version), fmt.Errorf("has no migration to version %d", latest)
+		}
+
+		m := migrations[i]
+		err := m.migrate(data)
+		if err != nil {
+			return fmt.Sprint(version), fmt.Errorf("failed to migrate to version %d: %w", m.to, err)
+		}
+
+		// the step may have set a version of its own
+		delete(data, versionDirective)
+		version = m.to
+	}
+
+	return fmt.Sprint(version), nil
+}
+
+// MigrateFile migrates the contents of a configuration file to the latest version of the migrations
+// registered with `WithMigration` and encodes it in the same format with the `version` key set,
+// formats are names or extensions i.e `json` or `.yml`. Comments are not preserved
+func (c *Config) MigrateFile(in []byte, format string) ([]byte, error) {
+	if len(c.migrations) == 0 {
+		return nil, errors.New("no migrations are registered")
+	}
+
+	err := c.validateMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	encoder, err := encoderFor(format)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := Parse(in, format)
+	if err != nil {
+		return nil, err
+	}
+
+	// the secret directive is not part of the layout
+	secrets, isDirective := isSecretDirective(data)
+	if isDirective {
+		delete(data, secretDirective)
+	}
+
+	version, err := c.migrateData(data)
+	if err != nil {
+		return nil, fmt.Errorf("version %s %w", version, err)
+	}
+
+	_, latest := c.versions()
+	data[versionDirective] = latest
+	if isDirective {
+		data[secretDirective] = secrets
+	}
+
+	return encoder.Encode(data)
+}
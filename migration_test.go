@@ -0,0 +1,170 @@
+package configo_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/affanshahid/configo"
+	"github.com/stretchr/testify/assert"
+)
+
+var migrations = []configo.ConfigOption{
+	configo.WithMigration(1, 2, func(data map[string]interface{}) error {
+		db, ok := data["db"].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if addr, found := db["addr"]; found {
+			db["host"] = addr
+			delete(db, "addr")
+		}
+		return nil
+	}),
+	configo.WithMigration(2, 3, func(data map[string]interface{}) error {
+		if port, found := data["port"]; found {
+			data["http"] = map[string]interface{}{"port": port}
+			delete(data, "port")
+		}
+		return nil
+	}),
+}
+
+func TestWithMigration(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                version: 3
+                db:
+                    host: localhost
+                http:
+                    port: 8080
+            `),
+		},
+		"local.yml": {
+			Data: []byte(`
+                db:
+                    addr: local.internal
+                port: 9090
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir, migrations...)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, map[string]interface{}{
+		"db":   map[string]interface{}{"host": "local.internal"},
+		"http": map[string]interface{}{"port": 9090},
+	}, config.AllSettings())
+
+	_, found := config.Position("port")
+	assert.False(t, found)
+
+	pos, found := config.Position("http.port")
+	assert.True(t, found)
+	assert.Equal(t, configo.Position{File: "local.yml", Line: 4, Column: 17}, pos)
+
+	pos, found = config.Position("db.host")
+	assert.True(t, found)
+	assert.Equal(t, configo.Position{File: "local.yml", Line: 3, Column: 21}, pos)
+}
+
+func TestWithMigrationErrors(t *testing.T) {
+	testCases := []struct {
+		name string
+		file string
+		opts []configo.ConfigOption
+		err  string
+	}{
+		{"too new", "version: 4\n", migrations, "default.yml:1:1: version 4 is later than the supported version 3, the file is newer than this build"},
+		{"not a number", "version: two\n", migrations, "default.yml:1:1: version two is not a whole number"},
+		{"fraction", "version: 1.5\n", migrations, "default.yml:1:1: version 1.5 is not a whole number"},
+		{"missing step", "version: 1\n", []configo.ConfigOption{configo.WithMigration(2, 3, func(map[string]interface{}) error { return nil })}, "default.yml:1:1: version 1 has no migration to version 3"},
+		{
+			"failed step",
+			"version: 1\n",
+			[]configo.ConfigOption{configo.WithMigration(1, 2, func(map[string]interface{}) error { return errors.New("boom") })},
+			"default.yml:1:1: version 1 failed to migrate to version 2: boom",
+		},
+		{"backwards", "a: 1\n", []configo.ConfigOption{configo.WithMigration(2, 1, func(map[string]interface{}) error { return nil })}, "migration from version 2 to 1 does not move forward"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := fstest.MapFS{"default.yml": {Data: []byte(tc.file)}}
+
+			config, err := configo.NewConfig(dir, tc.opts...)
+			assert.Nilf(t, err, "err should be nil")
+
+			err = config.Initialize()
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestMigrationWholeVersions(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.json": {Data: []byte(`{"version": 2.0, "db": {"host": "localhost"}, "port": 8080}`)},
+	}
+
+	config, err := configo.NewConfig(dir, migrations...)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, 8080, config.MustGetInt("http.port"))
+}
+
+func TestLintNewerVersion(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {Data: []byte("version: 3\ndb:\n  host: localhost\n")},
+		"local.yml":   {Data: []byte("version: 4\ncache:\n  ttl: 60\n")},
+	}
+
+	config, err := configo.NewConfig(dir, migrations...)
+	assert.Nilf(t, err, "err should be nil")
+
+	issues, err := config.Lint()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, []configo.LintIssue{{
+		Position: configo.Position{File: "local.yml", Line: 1, Column: 1},
+		Rule:     configo.LintNewerVersion,
+		Message:  "version 4 is later than the supported version 3, the file is newer than this build",
+	}}, issues)
+}
+
+func TestWithoutMigrations(t *testing.T) {
+	dir := fstest.MapFS{"default.yml": {Data: []byte("version: 1.2.0\n")}}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, "1.2.0", config.MustGetString("version"))
+}
+
+func TestMigrateFile(t *testing.T) {
+	config, err := configo.NewConfig(nil, migrations...)
+	assert.Nilf(t, err, "err should be nil")
+
+	out, err := config.MigrateFile([]byte(`{"db": {"addr": "a"}, "port": 1, "secret": ["db.host"]}`), "json")
+	assert.Nilf(t, err, "err should be nil")
+
+	data, err := configo.Parse(out, "json")
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, map[string]interface{}{
+		"db":      map[string]interface{}{"host": "a"},
+		"http":    map[string]interface{}{"port": float64(1)},
+		"secret":  []interface{}{"db.host"},
+		"version": float64(3),
+	}, data)
+
+	_, err = config.MigrateFile([]byte("version = 5\n"), ".toml")
+	assert.EqualError(t, err, "version 5 is later than the supported version 3, the file is newer than this build")
+}
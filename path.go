@@ -0,0 +1,244 @@
+package configo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Paths address values in the configurations and are shared by the getters, `Set`, `Sub`,
+// `Has`, secrets, positions and the mappings of `env.EXT`:
+//
+//	db.host                     keys separated by dots
+//	servers[0].port             indexes of slices in brackets
+//	servers.0.port              numeric keys also address items of slices
+//	hosts."api.example.com"     keys containing dots, brackets, quotes, `$` or whitespace are quoted
+//	hosts["api.example.com"]    quoted keys can also be written in brackets
+//	$.db.host                   a leading `$` refers to the root and is optional
+//
+// Quoted keys use double or single quotes and backslashes escape quotes and backslashes.
+// The empty path and `$` refer to the whole configuration.
+// Full JSONPath expressions are evaluated by `Query`
+
+// SplitPath splits a path into its keys, indexes are returned as numeric keys
+func SplitPath(path string) ([]string, error) {
+	p := &pathParser{path: path}
+
+	keys, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", path, err)
+	}
+
+	return keys, nil
+}
+
+// JoinPath joins keys into a path, quoting the keys which need to be quoted
+func JoinPath(keys ...string) string {
+	var b strings.Builder
+
+	for i, key := range keys {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(quoteKey(key))
+	}
+
+	return b.String()
+}
+
+// canonicalPath rewrites a path into the form used for positions and secrets,
+// invalid paths are returned unchanged so they simply match nothing
+func canonicalPath(path string) string {
+	p, err := compilePath(path)
+	if err != nil {
+		return path
+	}
+
+	return p.canonical
+}
+
+// joinPaths appends a path to another, both already in the form produced by `JoinPath`
+func joinPaths(parent, path string) string {
+	if parent == "" {
+		return path
+	}
+	if path == "" {
+		return parent
+	}
+
+	return parent + "." + path
+}
+
+func quoteKey(key string) string {
+	if key == "" || strings.ContainsAny(key, `.[]"'$\`) || strings.IndexFunc(key, unicode.IsSpace) >= 0 {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(key) + `"`
+	}
+
+	return key
+}
+
+type pathParser struct {
+	path string
+	pos  int
+}
+
+func (p *pathParser) parse() ([]string, error) {
+	if p.path == "$" {
+		return nil, nil
+	}
+	if strings.HasPrefix(p.path, "$.") || strings.HasPrefix(p.path, "$[") {
+		p.pos = 1
+		if p.path[1] == '.' {
+			p.pos = 2
+		}
+	}
+
+	if p.pos == len(p.path) {
+		if p.pos > 0 {
+			return nil, errors.New("missing key after $.")
+		}
+		return nil, nil
+	}
+
+	var keys []string
+	first := true
+
+	for p.pos < len(p.path) {
+		switch {
+		case p.path[p.pos] == '[':
+			key, err := p.bracket()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		case first || p.path[p.pos] == '.':
+			if !first {
+				p.pos++
+			}
+			key, err := p.key()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		default:
+			return nil, fmt.Errorf("unexpected %q at offset %d", p.path[p.pos], p.pos)
+		}
+
+		first = false
+	}
+
+	return keys, nil
+}
+
+// key reads a plain or quoted key
+func (p *pathParser) key() (string, error) {
+	if p.pos < len(p.path) && (p.path[p.pos] == '"' || p.path[p.pos] == '\'') {
+		return p.quoted()
+	}
+
+	start := p.pos
+	for p.pos < len(p.path) && p.path[p.pos] != '.' && p.path[p.pos] != '[' {
+		switch p.path[p.pos] {
+		case ']', '"', '\'':
+			return "", fmt.Errorf("unexpected %q at offset %d, quote the key", p.path[p.pos], p.pos)
+		}
+		p.pos++
+	}
+
+	if p.pos == start {
+		return "", fmt.Errorf("empty key at offset %d", start)
+	}
+
+	return p.path[start:p.pos], nil
+}
+
+// bracket reads `[0]`, `["key"]` or `['key']`
+func (p *pathParser) bracket() (string, error) {
+	start := p.pos
+	p.pos++
+
+	var key string
+	var err error
+
+	if p.pos < len(p.path) && (p.path[p.pos] == '"' || p.path[p.pos] == '\'') {
+		key, err = p.quoted()
+		if err != nil {
+			return "", err
+		}
+	} else {
+		end := strings.IndexByte(p.path[p.pos:], ']')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated bracket at offset %d", start)
+		}
+
+		key = p.path[p.pos : p.pos+end]
+		if _, err := strconv.ParseUint(key, 10, 0); err != nil {
+			return "", fmt.Errorf("invalid index %q at offset %d", key, start)
+		}
+		p.pos += end
+	}
+
+	if p.pos >= len(p.path) || p.path[p.pos] != ']' {
+		return "", fmt.Errorf("unterminated bracket at offset %d", start)
+	}
+	p.pos++
+
+	return key, nil
+}
+
+// quoted reads a key in double or single quotes
+func (p *pathParser) quoted() (string, error) {
+	start := p.pos
+	quote := p.path[p.pos]
+	p.pos++
+
+	var b strings.Builder
+	for p.pos < len(p.path) {
+		c := p.path[p.pos]
+		switch {
+		case c == '\\' && p.pos+1 < len(p.path):
+			b.WriteByte(p.path[p.pos+1])
+			p.pos += 2
+		case c == quote:
+			p.pos++
+			return b.String(), nil
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	return "", fmt.Errorf("unterminated quote at offset %d", start)
+}
+
+// Query evaluates a JSONPath expression, i.e `$.servers[*].port` or `$..host`,
+// and returns every match. Secrets are not masked in the results
+func (c *Config) Query(expr string) ([]interface{}, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("invalid query %q: expressions start with $", expr)
+	}
+
+	// wrapping the store in a slice makes every expression return a list of matches
+	eval, err := compileQuery("$[*]" + expr[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.store == nil {
+		return nil, ErrNotInitialized
+	}
+
+	out, err := eval(context.Background(), []interface{}{c.store})
+	if err != nil {
+		return nil, err
+	}
+
+	matches, _ := deepCopy(out).([]interface{})
+	return matches, nil
+}
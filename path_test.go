@@ -0,0 +1,194 @@
+package configo_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/affanshahid/configo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitPath(t *testing.T) {
+	tests := []struct {
+		path string
+		keys []string
+	}{
+		{"", nil},
+		{"$", nil},
+		{"db.host", []string{"db", "host"}},
+		{"$.db.host", []string{"db", "host"}},
+		{"servers[0].port", []string{"servers", "0", "port"}},
+		{"servers.0.port", []string{"servers", "0", "port"}},
+		{`hosts."api.example.com".port`, []string{"hosts", "api.example.com", "port"}},
+		{`hosts['api.example.com']`, []string{"hosts", "api.example.com"}},
+		{`$["hosts"]["a\"b"]`, []string{"hosts", `a"b`}},
+		{`"".key`, []string{"", "key"}},
+		{"matrix[1][2]", []string{"matrix", "1", "2"}},
+	}
+
+	for _, test := range tests {
+		keys, err := configo.SplitPath(test.path)
+		assert.Nilf(t, err, "err should be nil")
+		assert.Equal(t, test.keys, keys, test.path)
+	}
+
+	for _, path := range []string{"db.", ".db", "db..host", "servers[x]", "servers[0", `hosts."api`, "a]b", "$."} {
+		_, err := configo.SplitPath(path)
+		assert.NotNil(t, err, path)
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	assert.Equal(t, "db.host", configo.JoinPath("db", "host"))
+	assert.Equal(t, "servers.0.port", configo.JoinPath("servers", "0", "port"))
+	assert.Equal(t, `hosts."api.example.com"`, configo.JoinPath("hosts", "api.example.com"))
+	assert.Equal(t, `"a\"b".""`, configo.JoinPath(`a"b`, ""))
+
+	for _, keys := range [][]string{{"a.b", "c d", `e\f`, "$g"}, {"h[0]", "'i'"}} {
+		split, err := configo.SplitPath(configo.JoinPath(keys...))
+		assert.Nilf(t, err, "err should be nil")
+		assert.Equal(t, keys, split)
+	}
+}
+
+func TestPaths(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                hosts:
+                    api.example.com:
+                        port: 443
+                        token: s3cr3t
+                servers:
+                    - name: a
+                      port: 80
+                    - name: b
+                      port: 81
+                secret:
+                    - hosts."api.example.com".token
+            `),
+		},
+		"env.yml": {
+			Data: []byte(`
+                hosts:
+                    api.example.com:
+                        port: PATHS_API_PORT
+                servers:
+                    "1":
+                        port: PATHS_SERVER_PORT
+            `),
+		},
+	}
+
+	t.Setenv("PATHS_API_PORT", "8443")
+	t.Setenv("PATHS_SERVER_PORT", "8081")
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, 8443, config.MustGetInt(`hosts."api.example.com".port`))
+	assert.Equal(t, 8443, config.MustGetInt(`hosts["api.example.com"].port`))
+	assert.Equal(t, "a", config.MustGetString("servers[0].name"))
+	assert.Equal(t, 8081, config.MustGetInt("servers.1.port"))
+
+	assert.True(t, config.IsSecret(`hosts."api.example.com".token`))
+	assert.False(t, config.IsSecret(`hosts."api.example.com".port`))
+
+	pos, found := config.Position(`hosts['api.example.com'].port`)
+	assert.True(t, found)
+	assert.Equal(t, "env.yml", pos.File)
+
+	_, err = config.Get("hosts.api.example.com.port")
+	assert.NotNil(t, err)
+
+	_, err = config.Get("servers[2]")
+	assert.NotNil(t, err)
+
+	err = config.Set(`hosts."api.example.com".port`, 9443)
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, 9443, config.MustGetInt(`hosts."api.example.com".port`))
+
+	assert.Contains(t, config.AllKeys(), `hosts."api.example.com".port`)
+}
+
+func TestQuery(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                servers:
+                    - host: a
+                      port: 80
+                    - host: b
+                      port: 81
+                db:
+                    host: c
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	ports, err := config.Query("$.servers[*].port")
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, []interface{}{80, 81}, ports)
+
+	hosts, err := config.Query("$..host")
+	assert.Nilf(t, err, "err should be nil")
+	assert.ElementsMatch(t, []interface{}{"a", "b", "c"}, hosts)
+
+	host, err := config.Query("$.db.host")
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, []interface{}{"c"}, host)
+
+	_, err = config.Query("db.host")
+	assert.NotNil(t, err)
+}
+
+func TestSub(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                services:
+                    api.example.com:
+                        port: 443
+                        password: hunter2
+                secret:
+                    - services."api.example.com".password
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	sub, err := config.Sub(`services."api.example.com"`)
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, 443, sub.MustGetInt("port"))
+	assert.True(t, sub.IsSecret("password"))
+	assert.Equal(t, `{"password":"******","port":443}`, sub.String())
+
+	pos, found := sub.Position("port")
+	assert.True(t, found)
+	assert.Equal(t, "default.yml", pos.File)
+
+	err = sub.Set("port", 8443)
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, 443, config.MustGetInt(`services."api.example.com".port`))
+
+	_, err = config.Sub(`services."api.example.com".port`)
+	assert.NotNil(t, err)
+
+	err = sub.Initialize()
+	assert.NotNil(t, err)
+}
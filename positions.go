@@ -0,0 +1,476 @@
+package configo
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Position is a location inside a configuration file
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// String formats the position as `file:line:column`, omitting unknown parts
+func (p Position) String() string {
+	var b strings.Builder
+	b.WriteString(p.File)
+
+	if p.Line > 0 {
+		if b.Len() > 0 {
+			b.WriteString(":")
+		}
+		b.WriteString(strconv.Itoa(p.Line))
+
+		if p.Column > 0 {
+			b.WriteString(":")
+			b.WriteString(strconv.Itoa(p.Column))
+		}
+	}
+
+	return b.String()
+}
+
+// Positions maps paths, in the form produced by `JoinPath`, to the position where they are defined
+type Positions map[string]Position
+
+// ParseError is returned when a configuration file cannot be parsed.
+// Line and Column are zero when the underlying parser does not report them
+type ParseError struct {
+	Position
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	if pos := e.Position.String(); pos != "" {
+		return fmt.Sprintf("%s: %v", pos, e.Err)
+	}
+
+	return e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// joinKeyPath appends the key to the path, quoting it when needed
+func joinKeyPath(parent, key string) string {
+	if parent == "" {
+		return quoteKey(key)
+	}
+
+	return parent + "." + quoteKey(key)
+}
+
+// positionAt converts a byte offset into a 1-indexed line and column
+func positionAt(in []byte, offset int64) Position {
+	if offset > int64(len(in)) {
+		offset = int64(len(in))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	before := in[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := int(offset) - bytes.LastIndexByte(before, '\n')
+
+	return Position{Line: line, Column: column}
+}
+
+// jsonishScanner records key positions of JSON5 and HJSON documents.
+// It only tracks structure and does not validate the document, parsing
+// is left to the respective libraries.
+type jsonishScanner struct {
+	in        []byte
+	pos       int
+	hjson     bool
+	positions Positions
+}
+
+func scanJsonishPositions(in []byte, hjson bool) Positions {
+	s := &jsonishScanner{in: in, hjson: hjson, positions: Positions{}}
+
+	s.skipSpace()
+	if s.pos < len(s.in) && s.in[s.pos] != '{' && s.in[s.pos] != '[' && hjson {
+		// hjson allows omitting the braces of the root object
+		s.scanMembers("", 0)
+		return s.positions
+	}
+
+	s.scanValue("")
+	return s.positions
+}
+
+func (s *jsonishScanner) skipSpace() {
+	for s.pos < len(s.in) {
+		c := s.in[s.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			s.pos++
+		case c == '#' && s.hjson:
+			s.skipLine()
+		case c == '/' && s.pos+1 < len(s.in) && s.in[s.pos+1] == '/':
+			s.skipLine()
+		case c == '/' && s.pos+1 < len(s.in) && s.in[s.pos+1] == '*':
+			end := bytes.Index(s.in[s.pos+2:], []byte("*/"))
+			if end < 0 {
+				s.pos = len(s.in)
+			} else {
+				s.pos += end + 4
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (s *jsonishScanner) skipLine() {
+	for s.pos < len(s.in) && s.in[s.pos] != '\n' {
+		s.pos++
+	}
+}
+
+func (s *jsonishScanner) scanValue(path string) {
+	s.skipSpace()
+	if s.pos >= len(s.in) {
+		return
+	}
+
+	switch c := s.in[s.pos]; {
+	case c == '{':
+		s.pos++
+		s.scanMembers(path, '}')
+	case c == '[':
+		s.pos++
+		s.scanElements(path)
+	case s.hjson && bytes.HasPrefix(s.in[s.pos:], []byte("'''")):
+		end := bytes.Index(s.in[s.pos+3:], []byte("'''"))
+		if end < 0 {
+			s.pos = len(s.in)
+		} else {
+			s.pos += end + 6
+		}
+	case c == '"' || c == '\'':
+		s.scanQuoted()
+	default:
+		s.scanLiteral()
+	}
+}
+
+func (s *jsonishScanner) scanMembers(path string, closing byte) {
+	for {
+		s.skipSpace()
+		if s.pos >= len(s.in) {
+			return
+		}
+
+		switch s.in[s.pos] {
+		case closing:
+			s.pos++
+			return
+		case ',':
+			s.pos++
+			continue
+		}
+
+		pos := positionAt(s.in, int64(s.pos))
+		key, ok := s.scanKey()
+		if !ok {
+			return
+		}
+
+		keyPath := joinKeyPath(path, key)
+		s.positions[keyPath] = pos
+
+		s.skipSpace()
+		if s.pos < len(s.in) && s.in[s.pos] == ':' {
+			s.pos++
+		}
+
+		s.scanValue(keyPath)
+	}
+}
+
+func (s *jsonishScanner) scanElements(path string) {
+	for i := 0; ; {
+		s.skipSpace()
+		if s.pos >= len(s.in) {
+			return
+		}
+
+		switch s.in[s.pos] {
+		case ']':
+			s.pos++
+			return
+		case ',':
+			s.pos++
+			continue
+		}
+
+		elemPath := joinKeyPath(path, strconv.Itoa(i))
+		s.positions[elemPath] = positionAt(s.in, int64(s.pos))
+
+		start := s.pos
+		s.scanValue(elemPath)
+		if s.pos == start {
+			// avoid looping forever on input we do not understand
+			s.pos++
+		}
+		i++
+	}
+}
+
+func (s *jsonishScanner) scanKey() (string, bool) {
+	c := s.in[s.pos]
+	if c == '"' || c == '\'' {
+		start := s.pos
+		s.scanQuoted()
+		if s.pos > len(s.in) || s.pos < start+2 {
+			// unterminated string
+			return "", false
+		}
+
+		raw := string(s.in[start+1 : s.pos-1])
+		if c == '\'' {
+			return strings.ReplaceAll(raw, `\'`, `'`), true
+		}
+
+		if key, err := strconv.Unquote(`"` + raw + `"`); err == nil {
+			return key, true
+		}
+		return raw, true
+	}
+
+	start := s.pos
+	for s.pos < len(s.in) {
+		c := s.in[s.pos]
+		if c == ':' || c == ',' || c == '{' || c == '}' || c == '[' || c == ']' ||
+			c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+			break
+		}
+		s.pos++
+	}
+
+	if s.pos == start {
+		return "", false
+	}
+
+	return string(s.in[start:s.pos]), true
+}
+
+func (s *jsonishScanner) scanQuoted() {
+	quote := s.in[s.pos]
+	s.pos++
+	for s.pos < len(s.in) {
+		switch s.in[s.pos] {
+		case '\\':
+			s.pos += 2
+			continue
+		case quote:
+			s.pos++
+			return
+		}
+		s.pos++
+	}
+}
+
+func (s *jsonishScanner) scanLiteral() {
+	start := s.pos
+	for s.pos < len(s.in) {
+		c := s.in[s.pos]
+		if c == '\n' || c == '\r' {
+			return
+		}
+
+		if c == ',' || c == '}' || c == ']' {
+			// hjson quoteless strings run until the end of the line unless
+			// the literal is a number or keyword
+			if !s.hjson || isJsonishKeyword(bytes.TrimSpace(s.in[start:s.pos])) {
+				return
+			}
+		}
+
+		if !s.hjson && (c == ' ' || c == '\t' || c == '/') {
+			return
+		}
+
+		s.pos++
+	}
+}
+
+func isJsonishKeyword(lit []byte) bool {
+	switch string(lit) {
+	case "true", "false", "null":
+		return true
+	}
+
+	_, err := strconv.ParseFloat(string(lit), 64)
+	return err == nil
+}
+
+// scanTomlPositions records key positions of a TOML document line by line
+func scanTomlPositions(in []byte) Positions {
+	positions := Positions{}
+	arrayTables := map[string]int{}
+	table := ""
+
+	lines := strings.Split(string(in), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed[0] == '#' {
+			continue
+		}
+
+		column := strings.Index(line, trimmed) + 1
+
+		if strings.HasPrefix(trimmed, "[[") {
+			end := strings.Index(trimmed, "]]")
+			if end < 0 {
+				continue
+			}
+			name := JoinPath(splitTomlKey(trimmed[2:end])...)
+			idx := arrayTables[name]
+			arrayTables[name] = idx + 1
+			table = joinKeyPath(name, strconv.Itoa(idx))
+			positions[name] = Position{Line: i + 1, Column: column}
+			positions[table] = Position{Line: i + 1, Column: column}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") {
+			end := strings.Index(trimmed, "]")
+			if end < 0 {
+				continue
+			}
+			table = JoinPath(splitTomlKey(trimmed[1:end])...)
+			positions[table] = Position{Line: i + 1, Column: column}
+			continue
+		}
+
+		eq := tomlKeyEnd(trimmed)
+		if eq < 0 {
+			continue
+		}
+
+		keyPath := table
+		for _, k := range splitTomlKey(trimmed[:eq]) {
+			keyPath = joinKeyPath(keyPath, k)
+			if _, found := positions[keyPath]; !found {
+				positions[keyPath] = Position{Line: i + 1, Column: column}
+			}
+		}
+		positions[keyPath] = Position{Line: i + 1, Column: column}
+
+		i += tomlValueExtraLines(trimmed[eq+1:], lines[i+1:])
+	}
+
+	return positions
+}
+
+// tomlKeyEnd returns the index of the `=` separating a key from its value
+func tomlKeyEnd(line string) int {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '=':
+			return i
+		}
+	}
+
+	return -1
+}
+
+func splitTomlKey(key string) []string {
+	var parts []string
+	var cur strings.Builder
+	var quote byte
+
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' && i+1 < len(key) {
+				i++
+				cur.WriteByte(key[i])
+			} else if c == quote {
+				quote = 0
+			} else {
+				cur.WriteByte(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '.':
+			parts = append(parts, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		case c == ' ' || c == '\t':
+		default:
+			cur.WriteByte(c)
+		}
+	}
+
+	return append(parts, strings.TrimSpace(cur.String()))
+}
+
+// tomlValueExtraLines returns how many of the following lines belong to a
+// value which spans multiple lines, i.e multi-line strings and arrays
+func tomlValueExtraLines(value string, rest []string) int {
+	depth := 0
+	var quote string
+
+	scan := func(s string) {
+		for i := 0; i < len(s); i++ {
+			if quote != "" {
+				if strings.HasPrefix(s[i:], quote) {
+					i += len(quote) - 1
+					quote = ""
+				} else if s[i] == '\\' && quote[0] == '"' {
+					i++
+				}
+				continue
+			}
+
+			switch {
+			case strings.HasPrefix(s[i:], `"""`), strings.HasPrefix(s[i:], `'''`):
+				quote = s[i : i+3]
+				i += 2
+			case s[i] == '"' || s[i] == '\'':
+				quote = s[i : i+1]
+			case s[i] == '[' || s[i] == '{':
+				depth++
+			case s[i] == ']' || s[i] == '}':
+				depth--
+			case s[i] == '#':
+				return
+			}
+		}
+
+		// single line strings can not span lines
+		if len(quote) == 1 {
+			quote = ""
+		}
+	}
+
+	scan(value)
+
+	extra := 0
+	for extra < len(rest) && (depth > 0 || quote != "") {
+		scan(rest[extra])
+		extra++
+	}
+
+	return extra
+}
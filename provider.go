@@ -4,3 +4,18 @@ package configo
 type Provider interface {
 	Parse(in []byte) (map[string]interface{}, error)
 }
+
+// PositionProvider is a Provider which also reports the position
+// of every key path it parsed.
+// Positions are keyed by dotted key paths with array elements
+// addressed by their index i.e `servers.0.port`
+type PositionProvider interface {
+	Provider
+	ParsePositions(in []byte) (map[string]interface{}, Positions, error)
+}
+
+// Encoder is an interface for serialising a map into binary data.
+// The built-in providers of every format implement it
+type Encoder interface {
+	Encode(data map[string]interface{}) ([]byte, error)
+}
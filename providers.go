@@ -1,7 +1,15 @@
 package configo
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/flynn/json5"
 	"github.com/hjson/hjson-go"
@@ -15,58 +23,299 @@ func (p ProviderFunc) Parse(in []byte) (map[string]interface{}, error) {
 	return p(in)
 }
 
-func parseYaml(in []byte) (map[string]interface{}, error) {
+// PositionProviderFunc is a function implementing PositionProvider
+type PositionProviderFunc func(in []byte) (map[string]interface{}, Positions, error)
+
+func (p PositionProviderFunc) Parse(in []byte) (map[string]interface{}, error) {
+	data, _, err := p(in)
+	return data, err
+}
+
+func (p PositionProviderFunc) ParsePositions(in []byte) (map[string]interface{}, Positions, error) {
+	return p(in)
+}
+
+var yamlLineRegexp = regexp.MustCompile(`line (\d+)`)
+
+func parseYaml(in []byte) (map[string]interface{}, Positions, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal(in, &doc)
+	if err != nil {
+		return nil, nil, yamlParseError(err, in)
+	}
+
 	data := map[string]interface{}{}
-	err := yaml.Unmarshal(in, &data)
+	positions := Positions{}
+	if len(doc.Content) == 0 {
+		return data, positions, nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind == yaml.ScalarNode && root.ShortTag() == "!!null" {
+		// `---`, `~` and `null` are empty documents
+		return data, positions, nil
+	}
+
+	if root.Kind != yaml.MappingNode {
+		return nil, nil, &ParseError{
+			Position: Position{Line: root.Line, Column: root.Column},
+			Err:      fmt.Errorf("expected a mapping at the root of the document"),
+		}
+	}
+
+	err = root.Decode(&data)
 	if err != nil {
-		return nil, err
+		return nil, nil, yamlParseError(err, in)
+	}
+
+	yamlPositions(root, "", positions)
+	return data, positions, nil
+}
+
+// yamlParseError converts errors of yaml.v3, which only report the line, into a ParseError.
+// The column is the first character of the offending line
+func yamlParseError(err error, in []byte) error {
+	perr := &ParseError{Err: err}
+	if match := yamlLineRegexp.FindStringSubmatch(err.Error()); match != nil {
+		perr.Line, _ = strconv.Atoi(match[1])
+		perr.Column = yamlLineColumn(in, perr.Line)
+	}
+
+	return perr
+}
+
+// yamlLineColumn returns the column of the first non blank character of the line, 0 if there is none
+func yamlLineColumn(in []byte, line int) int {
+	lines := strings.Split(string(in), "\n")
+	if line < 1 || line > len(lines) {
+		return 0
+	}
+
+	text := strings.TrimRight(lines[line-1], "\r")
+	trimmed := strings.TrimLeft(text, " \t")
+	if trimmed == "" {
+		return 0
 	}
 
-	return data, nil
+	return utf8.RuneCountInString(text[:len(text)-len(trimmed)]) + 1
+}
+
+func yamlPositions(node *yaml.Node, path string, positions Positions) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := joinKeyPath(path, key.Value)
+			positions[keyPath] = Position{Line: key.Line, Column: key.Column}
+			yamlPositions(value, keyPath, positions)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			itemPath := joinKeyPath(path, strconv.Itoa(i))
+			positions[itemPath] = Position{Line: item.Line, Column: item.Column}
+			yamlPositions(item, itemPath, positions)
+		}
+	}
 }
 
-func parseJson(in []byte) (map[string]interface{}, error) {
+func parseJson(in []byte) (map[string]interface{}, Positions, error) {
 	data := map[string]interface{}{}
 	err := json.Unmarshal(in, &data)
 	if err != nil {
-		return nil, err
+		return nil, nil, jsonParseError(in, err)
+	}
+
+	positions := Positions{}
+	dec := json.NewDecoder(bytes.NewReader(in))
+	err = jsonPositions(in, dec, "", positions)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, jsonParseError(in, err)
 	}
 
-	return data, nil
+	return data, positions, nil
 }
 
-func parseJson5(in []byte) (map[string]interface{}, error) {
+func jsonParseError(in []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		return &ParseError{Position: positionAt(in, syntaxErr.Offset-1), Err: err}
+	case errors.As(err, &typeErr):
+		return &ParseError{Position: positionAt(in, typeErr.Offset-1), Err: err}
+	}
+
+	return &ParseError{Err: err}
+}
+
+// jsonPositions walks the tokens of the next JSON value recording the position of every key
+func jsonPositions(in []byte, dec *json.Decoder, path string, positions Positions) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			start := skipJsonSeparators(in, dec.InputOffset())
+
+			key, err := dec.Token()
+			if err != nil {
+				return err
+			}
+
+			keyPath := joinKeyPath(path, fmt.Sprint(key))
+			positions[keyPath] = positionAt(in, start)
+
+			err = jsonPositions(in, dec, keyPath, positions)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			elemPath := joinKeyPath(path, strconv.Itoa(i))
+			positions[elemPath] = positionAt(in, skipJsonSeparators(in, dec.InputOffset()))
+
+			err = jsonPositions(in, dec, elemPath, positions)
+			if err != nil {
+				return err
+			}
+		}
+		_, err = dec.Token()
+	}
+
+	return err
+}
+
+func skipJsonSeparators(in []byte, offset int64) int64 {
+	for offset < int64(len(in)) {
+		switch in[offset] {
+		case ' ', '\t', '\r', '\n', ',', ':':
+			offset++
+		default:
+			return offset
+		}
+	}
+
+	return offset
+}
+
+func parseJson5(in []byte) (map[string]interface{}, Positions, error) {
 	data := map[string]interface{}{}
 	err := json5.Unmarshal(in, &data)
 	if err != nil {
-		return nil, err
+		var syntaxErr *json5.SyntaxError
+		var typeErr *json5.UnmarshalTypeError
+
+		switch {
+		case errors.As(err, &syntaxErr):
+			return nil, nil, &ParseError{Position: positionAt(in, syntaxErr.Offset-1), Err: err}
+		case errors.As(err, &typeErr):
+			return nil, nil, &ParseError{Position: positionAt(in, typeErr.Offset-1), Err: err}
+		}
+
+		return nil, nil, &ParseError{Err: err}
 	}
 
-	return data, nil
+	return data, scanJsonishPositions(in, false), nil
 }
 
-func parseHjson(in []byte) (map[string]interface{}, error) {
+var hjsonPositionRegexp = regexp.MustCompile(`at line (\d+),(\d+)`)
+
+func parseHjson(in []byte) (map[string]interface{}, Positions, error) {
 	data := map[string]interface{}{}
 	err := hjson.Unmarshal(in, &data)
 	if err != nil {
-		return nil, err
+		perr := &ParseError{Err: err}
+		if match := hjsonPositionRegexp.FindStringSubmatch(err.Error()); match != nil {
+			perr.Line, _ = strconv.Atoi(match[1])
+			perr.Column, _ = strconv.Atoi(match[2])
+		}
+
+		return nil, nil, perr
 	}
 
-	return data, nil
+	return data, scanJsonishPositions(in, true), nil
 }
 
-func parseToml(in []byte) (map[string]interface{}, error) {
+func parseToml(in []byte) (map[string]interface{}, Positions, error) {
 	data := map[string]interface{}{}
 	err := toml.Unmarshal(in, &data)
+	if err != nil {
+		perr := &ParseError{Err: err}
+
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			perr.Line, perr.Column = decodeErr.Position()
+		}
+
+		return nil, nil, perr
+	}
+
+	return data, scanTomlPositions(in), nil
+}
+
+// EncoderFunc is a function implementing Encoder
+type EncoderFunc func(data map[string]interface{}) ([]byte, error)
+
+func (e EncoderFunc) Encode(data map[string]interface{}) ([]byte, error) {
+	return e(data)
+}
+
+func encodeYaml(data map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	err := enc.Encode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = enc.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeJson is also used for json5 as every JSON document is valid JSON5
+func encodeJson(data map[string]interface{}) ([]byte, error) {
+	out, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
 		return nil, err
 	}
 
-	return data, nil
+	return append(out, '\n'), nil
+}
+
+func encodeHjson(data map[string]interface{}) ([]byte, error) {
+	out, err := hjson.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(out, '\n'), nil
+}
+
+func encodeToml(data map[string]interface{}) ([]byte, error) {
+	return toml.Marshal(data)
+}
+
+// formatProvider parses and encodes a format
+type formatProvider struct {
+	PositionProviderFunc
+	EncoderFunc
 }
 
-var yamlProvider = ProviderFunc(parseYaml)
-var jsonProvider = ProviderFunc(parseJson)
-var json5Provider = ProviderFunc(parseJson5)
-var hjsonProvider = ProviderFunc(parseHjson)
-var tomlProvider = ProviderFunc(parseToml)
+var yamlProvider = formatProvider{parseYaml, encodeYaml}
+var jsonProvider = formatProvider{parseJson, encodeJson}
+var json5Provider = formatProvider{parseJson5, encodeJson}
+var hjsonProvider = formatProvider{parseHjson, encodeHjson}
+var tomlProvider = formatProvider{parseToml, encodeToml}
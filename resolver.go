@@ -0,0 +1,198 @@
+package configo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// Resolver resolves references such as `file:///run/secrets/db_password`
+// into the values they point to
+type Resolver interface {
+	Resolve(ctx context.Context, ref *url.URL) (string, error)
+}
+
+// ResolverFunc is a function implementing Resolver
+type ResolverFunc func(ctx context.Context, ref *url.URL) (string, error)
+
+func (r ResolverFunc) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	return r(ctx, ref)
+}
+
+// ResolveError is returned when a reference can not be resolved
+type ResolveError struct {
+	Path     string
+	Position Position
+	Ref      string
+	Err      error
+}
+
+func (e *ResolveError) Error() string {
+	if pos := e.Position.String(); pos != "" {
+		return fmt.Sprintf("%s: unable to resolve %s (%s): %v", pos, e.Path, e.Ref, e.Err)
+	}
+
+	return fmt.Sprintf("unable to resolve %s (%s): %v", e.Path, e.Ref, e.Err)
+}
+
+func (e *ResolveError) Unwrap() error {
+	return e.Err
+}
+
+// WithResolver registers a resolver for references with the given scheme.
+// References are resolved during `Initialize` and the resolved values are treated as secrets
+func WithResolver(scheme string, r Resolver) ConfigOption {
+	return func(c *Config) {
+		c.resolvers[strings.ToLower(scheme)] = r
+	}
+}
+
+// FileResolver resolves `file:///path/to/file` references to the contents
+// of the file with trailing newlines removed, as used by Docker and Kubernetes secrets
+var FileResolver Resolver = ResolverFunc(resolveFile)
+
+// EnvResolver resolves `env://NAME` references to the value of the environment variable
+var EnvResolver Resolver = ResolverFunc(resolveEnv)
+
+func resolveFile(ctx context.Context, ref *url.URL) (string, error) {
+	path := ref.Path
+	if ref.Opaque != "" {
+		path = ref.Opaque
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func resolveEnv(ctx context.Context, ref *url.URL) (string, error) {
+	name := ref.Host
+	if ref.Opaque != "" {
+		name = ref.Opaque
+	}
+
+	value, found := LookupEnv(ctx, name)
+	if !found {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+
+	return value, nil
+}
+
+// VaultResolver resolves `vault://mount/data/path#field` references
+// using the HashiCorp Vault KV version 2 HTTP API
+type VaultResolver struct {
+	// Address of the Vault server, defaults to the `VAULT_ADDR` environment variable
+	Address string
+
+	// Token used to authenticate, defaults to the `VAULT_TOKEN` environment variable
+	Token string
+
+	// Client used for requests, defaults to a client with a 10 second timeout
+	Client *http.Client
+}
+
+func (v *VaultResolver) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	if ref.Fragment == "" {
+		return "", errors.New("missing field, expected vault://mount/data/path#field")
+	}
+
+	address := v.Address
+	if address == "" {
+		address, _ = LookupEnv(ctx, "VAULT_ADDR")
+	}
+
+	token := v.Token
+	if token == "" {
+		token, _ = LookupEnv(ctx, "VAULT_TOKEN")
+	}
+
+	client := v.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	endpoint := strings.TrimSuffix(address, "/") + "/v1/" + ref.Host + ref.Path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+
+	res, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault responded with %s", res.Status)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&body)
+	if err != nil {
+		return "", err
+	}
+
+	value, found := body.Data.Data[ref.Fragment]
+	if !found {
+		return "", fmt.Errorf("field %s not found", ref.Fragment)
+	}
+
+	return cast.ToStringE(value)
+}
+
+// resolveValues resolves all references in the store in place
+func (c *Config) resolveValues(ctx context.Context, s *snapshot) error {
+	if len(c.resolvers) == 0 {
+		return nil
+	}
+
+	resolved := map[string]string{}
+
+	return transformValues(s.store, func(path string, value interface{}) (interface{}, error) {
+		str, ok := value.(string)
+		if !ok || !strings.Contains(str, ":") {
+			return value, nil
+		}
+
+		ref, err := url.Parse(str)
+		if err != nil {
+			return value, nil
+		}
+
+		resolver, found := c.resolvers[ref.Scheme]
+		if !found {
+			return value, nil
+		}
+
+		out, found := resolved[str]
+		if !found {
+			out, err = resolver.Resolve(ctx, ref)
+			if err != nil {
+				return nil, &ResolveError{Path: path, Position: s.positions[path], Ref: str, Err: err}
+			}
+			resolved[str] = out
+		}
+
+		s.secrets[path] = struct{}{}
+		return out, nil
+	})
+}
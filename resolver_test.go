@@ -0,0 +1,134 @@
+package configo_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/affanshahid/configo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileAndEnvResolvers(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "db_password")
+	err := os.WriteFile(secretFile, []byte("hunter2\n"), 0600)
+	assert.Nilf(t, err, "err should be nil")
+
+	t.Setenv("API_KEY", "s3cr3t")
+
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                db:
+                    password: file://` + secretFile + `
+                api:
+                    key: env://API_KEY
+                    url: https://example.com
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(
+		dir,
+		configo.WithResolver("file", configo.FileResolver),
+		configo.WithResolver("env", configo.EnvResolver),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, "hunter2", config.MustGetString("db.password"))
+	assert.Equal(t, "s3cr3t", config.MustGetString("api.key"))
+	assert.Equal(t, "https://example.com", config.MustGetString("api.url"))
+	assert.True(t, config.IsSecret("db.password"))
+	assert.False(t, config.IsSecret("api.url"))
+}
+
+func TestEnvResolverLookup(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                api:
+                    key: env://LOOKUP_API_KEY
+            `),
+		},
+	}
+
+	lookup := func(name string) (string, bool) {
+		if name == "LOOKUP_API_KEY" {
+			return "s3cr3t", true
+		}
+		return "", false
+	}
+
+	config, err := configo.NewConfig(
+		dir,
+		configo.WithResolver("env", configo.EnvResolver),
+		configo.WithEnvLookup(lookup),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, "s3cr3t", config.MustGetString("api.key"))
+}
+
+func TestVaultResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		if r.URL.Path != "/v1/secret/data/db" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Write([]byte(`{"data": {"data": {"password": "hunter2"}, "metadata": {"version": 1}}}`))
+	}))
+	defer server.Close()
+
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                db:
+                    password: vault://secret/data/db#password
+                    user: vault://secret/data/db#user
+                cache:
+                    password: vault://secret/data/cache#password
+            `),
+		},
+	}
+
+	resolver := &configo.VaultResolver{Address: server.URL, Token: "token"}
+
+	config, err := configo.NewConfig(dir, configo.WithResolver("vault", resolver))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.NotNil(t, err)
+
+	var resolveErr *configo.ResolveError
+	assert.True(t, errors.As(err, &resolveErr))
+	assert.Equal(t, "cache.password", resolveErr.Path)
+	assert.Contains(t, err.Error(), "db.user")
+	assert.Contains(t, err.Error(), "cache.password")
+
+	dir["default.yml"] = &fstest.MapFile{
+		Data: []byte(`
+            db:
+                password: vault://secret/data/db#password
+        `),
+	}
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, "hunter2", config.MustGetString("db.password"))
+}
@@ -0,0 +1,203 @@
+package configo
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const secretMask = "******"
+
+// secretDirective is the top-level key which lists the secret paths of a file,
+// it is reserved whenever it holds a list of strings, see `isSecretDirective`
+const secretDirective = "secret"
+
+// Secret holds a sensitive configuration value.
+// It is masked when printed or marshalled, use `Value` to access the actual value
+type Secret struct {
+	value string
+}
+
+// NewSecret wraps the given value in a Secret
+func NewSecret(value string) Secret {
+	return Secret{value}
+}
+
+// Value returns the unmasked value
+func (s Secret) Value() string {
+	return s.value
+}
+
+// String returns the masked value
+func (s Secret) String() string {
+	return secretMask
+}
+
+// GoString returns the masked value
+func (s Secret) GoString() string {
+	return fmt.Sprintf("configo.Secret(%q)", secretMask)
+}
+
+// MarshalJSON marshals the masked value
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(secretMask)
+}
+
+// MarshalText marshals the masked value
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(secretMask), nil
+}
+
+// MarshalYAML marshals the masked value
+func (s Secret) MarshalYAML() (interface{}, error) {
+	return secretMask, nil
+}
+
+// WithSecrets marks the values at the given paths as secrets.
+// Marking a path also marks everything nested under it.
+//
+// Secrets can also be declared in any configuration file
+// using the top-level `secret` directive:
+//
+//	secret:
+//	  - db.password
+//	  - api.keys
+//
+// A top-level `secret` key holding a list of strings is always read as the directive
+// and is not part of the configurations, any other top-level `secret` value is kept as is
+func WithSecrets(paths ...string) ConfigOption {
+	return func(c *Config) {
+		for _, path := range paths {
+			c.secretPaths[canonicalPath(path)] = struct{}{}
+		}
+	}
+}
+
+// IsSecret reports whether the value at the given path is a secret
+func (c *Config) IsSecret(path string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.isSecret(path)
+}
+
+func (s *snapshot) isSecret(path string) bool {
+	if len(s.secrets) == 0 {
+		return false
+	}
+
+	p, err := compilePath(path)
+	if err != nil {
+		return false
+	}
+
+	if _, found := s.secrets[p.canonical]; found {
+		return true
+	}
+
+	for i := len(p.keys) - 1; i > 0; i-- {
+		if _, found := s.secrets[JoinPath(p.keys[:i]...)]; found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// String returns the loaded configurations as JSON with secrets masked
+func (c *Config) String() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	out, err := json.Marshal(c.redact(c.store, ""))
+	if err != nil {
+		return fmt.Sprintf("configo.Config(%v)", err)
+	}
+
+	return string(out)
+}
+
+// Redacted returns a copy of the loaded configurations with secrets masked
+func (c *Config) Redacted() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	redacted, _ := c.redact(c.store, "").(map[string]interface{})
+	return redacted
+}
+
+// GoString returns the loaded configurations as JSON with secrets masked
+func (c *Config) GoString() string {
+	return c.String()
+}
+
+// isSecretDirective returns the paths of the `secret` directive of the data,
+// a top-level `secret` key which is not a list of strings is an ordinary key
+func isSecretDirective(data map[string]interface{}) ([]interface{}, bool) {
+	paths, ok := data[secretDirective].([]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	for _, p := range paths {
+		if _, ok := p.(string); !ok {
+			return nil, false
+		}
+	}
+
+	return paths, true
+}
+
+// readSecretDirective removes the `secret` directive from the data
+// and marks the paths it lists as secrets
+func (s *snapshot) readSecretDirective(data map[string]interface{}, positions Positions) {
+	paths, ok := isSecretDirective(data)
+	if !ok {
+		return
+	}
+
+	for _, p := range paths {
+		s.secrets[canonicalPath(p.(string))] = struct{}{}
+	}
+
+	delete(data, secretDirective)
+	for path := range positions {
+		if path == secretDirective || strings.HasPrefix(path, secretDirective+".") {
+			delete(positions, path)
+		}
+	}
+}
+
+// redact returns a deep copy of the value with all secrets masked
+func (s *snapshot) redact(value interface{}, path string) interface{} {
+	if path != "" && s.isSecret(path) {
+		return secretMask
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			out[key] = s.redact(val, joinKeyPath(path, key))
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = s.redact(val, joinKeyPath(path, strconv.Itoa(i)))
+		}
+		return out
+	}
+
+	return value
+}
+
+// redactError hides errors which might contain the value of a secret
+func (c *Config) redactError(path string, err error) error {
+	if err == nil || !c.IsSecret(path) {
+		return err
+	}
+
+	return fmt.Errorf("unable to convert secret value at %s", path)
+}
@@ -0,0 +1,133 @@
+package configo_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/affanshahid/configo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecret(t *testing.T) {
+	secret := configo.NewSecret("hunter2")
+
+	assert.Equal(t, "hunter2", secret.Value())
+	assert.Equal(t, "******", secret.String())
+	assert.Equal(t, "******", fmt.Sprintf("%v", secret))
+	assert.NotContains(t, fmt.Sprintf("%#v", secret), "hunter2")
+
+	out, err := json.Marshal(map[string]interface{}{"password": secret})
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, `{"password":"******"}`, string(out))
+}
+
+func TestWithSecrets(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                db:
+                    user: admin
+                    password: hunter2
+                api:
+                    keys:
+                        - key1
+                        - key2
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir, configo.WithSecrets("db.password", "api"))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.True(t, config.IsSecret("db.password"))
+	assert.True(t, config.IsSecret("api.keys"))
+	assert.False(t, config.IsSecret("db.user"))
+
+	secret, err := config.GetSecret("db.password")
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, "hunter2", secret.Value())
+
+	assert.Equal(t, `{"api":"******","db":{"password":"******","user":"admin"}}`, config.String())
+	assert.NotContains(t, fmt.Sprintf("%v", config), "hunter2")
+}
+
+func TestSecretDirective(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                db:
+                    password: hunter2
+            `),
+		},
+		"production.yml": {
+			Data: []byte(`
+                secret:
+                    - db.password
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir, configo.WithDeployment("production"))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.True(t, config.IsSecret("db.password"))
+	assert.Equal(t, `{"db":{"password":"******"}}`, config.String())
+
+	val, _ := config.Get("secret")
+	assert.Nil(t, val)
+}
+
+func TestSecretKeyNotDirective(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                secret:
+                    key: abc
+            `),
+		},
+		"production.yml": {
+			Data: []byte(`
+                secret:
+                    key: [1, 2]
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir, configo.WithDeployment("production"))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, []interface{}{1, 2}, config.MustGet("secret.key"))
+	assert.False(t, config.IsSecret("secret.key"))
+}
+
+func TestSecretConversionError(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                db:
+                    password: hunter2
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir, configo.WithSecrets("db.password"))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	_, err = config.GetInt("db.password")
+	assert.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "hunter2")
+}
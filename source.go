@@ -0,0 +1,221 @@
+package configo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// Source is a configuration layer which is not read from the configuration directory,
+// such as a remote store, command line arguments or computed values
+type Source interface {
+	Load(ctx context.Context) (map[string]interface{}, error)
+}
+
+// WatchableSource is a Source which can report changes, see `Config.Watch`
+type WatchableSource interface {
+	Source
+
+	// Watch blocks until ctx is done or watching fails,
+	// calling onChange whenever the source has changed
+	Watch(ctx context.Context, onChange func()) error
+}
+
+// PositionSource is a Source which also reports where each key path was defined
+type PositionSource interface {
+	Source
+	LoadPositions(ctx context.Context) (map[string]interface{}, Positions, error)
+}
+
+const (
+	stageBeforeFiles = iota
+	stageAfterTemplate
+	stageAfterFiles
+	stageAfterEnv
+)
+
+// Precedence decides where a Source is merged in relation to the configuration files
+// and the environment variable overrides. Sources with the same precedence are merged
+// in the order they were added
+type Precedence struct {
+	stage    int
+	template string
+}
+
+var (
+	// BeforeFiles merges the source first, all configuration files override its values
+	BeforeFiles = Precedence{stage: stageBeforeFiles}
+
+	// AfterFiles merges the source after the configuration files
+	// and before the environment variable overrides
+	AfterFiles = Precedence{stage: stageAfterFiles}
+
+	// AfterEnv merges the source last, its values override everything else
+	AfterEnv = Precedence{stage: stageAfterEnv}
+)
+
+// AfterTemplate merges the source right after the file matching the given template,
+// i.e `AfterTemplate("{deployment}")` merges the source after `{deployment}.EXT` and before
+// `{deployment}-{instance}.EXT`. The source is merged even when no such file exists
+func AfterTemplate(tmpl string) Precedence {
+	return Precedence{stage: stageAfterTemplate, template: tmpl}
+}
+
+type sourceEntry struct {
+	source     Source
+	precedence Precedence
+}
+
+// WithSource merges the given source at the given precedence
+func WithSource(source Source, precedence Precedence) ConfigOption {
+	return func(c *Config) {
+		c.sources = append(c.sources, sourceEntry{source, precedence})
+	}
+}
+
+// validateSources ensures all templates used as precedence exist
+func (c *Config) validateSources() error {
+	for _, entry := range c.sources {
+		if entry.precedence.stage != stageAfterTemplate {
+			continue
+		}
+
+		found := false
+		for _, tmpl := range orderedTemplates {
+			if tmpl == entry.precedence.template {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("unknown template %s for source %s", entry.precedence.template, sourceName(entry.source))
+		}
+	}
+
+	return nil
+}
+
+// mergeSources merges all sources with the given precedence into the snapshot
+func (c *Config) mergeSources(ctx context.Context, s *snapshot, precedence Precedence) error {
+	for _, entry := range c.sources {
+		if entry.precedence != precedence {
+			continue
+		}
+
+		data, positions, err := loadSource(ctx, entry.source)
+		if err != nil {
+			return fmt.Errorf("%s: %w", sourceName(entry.source), err)
+		}
+
+		// sources may hold on to the data they return
+		data = deepCopy(data).(map[string]interface{})
+
+		err = c.migrateDeprecated(data, positions, c.reportDeprecation)
+		if err != nil {
+			return err
+		}
+
+		err = s.merge(data, positions)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func loadSource(ctx context.Context, source Source) (map[string]interface{}, Positions, error) {
+	if ps, ok := source.(PositionSource); ok {
+		return ps.LoadPositions(ctx)
+	}
+
+	data, err := source.Load(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	positions := Positions{}
+	pos := Position{File: sourceName(source)}
+	collectPositions(data, "", pos, positions)
+
+	return data, positions, nil
+}
+
+// collectPositions assigns the same position to every key path in value
+func collectPositions(value interface{}, path string, pos Position, positions Positions) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			keyPath := joinKeyPath(path, key)
+			positions[keyPath] = pos
+			collectPositions(val, keyPath, pos, positions)
+		}
+	case []interface{}:
+		for i, val := range v {
+			itemPath := joinKeyPath(path, strconv.Itoa(i))
+			positions[itemPath] = pos
+			collectPositions(val, itemPath, pos, positions)
+		}
+	}
+}
+
+// sourceName describes the source for errors and positions
+func sourceName(source Source) string {
+	if s, ok := source.(fmt.Stringer); ok {
+		return s.String()
+	}
+
+	return fmt.Sprintf("%T", source)
+}
+
+// Watch watches every source implementing WatchableSource and reloads the configurations
+// whenever one of them changes. onReload, when not nil, is called with the result of every reload.
+//
+// Watch blocks until ctx is done or a source fails to watch.
+// It returns immediately if there are no watchable sources
+func (c *Config) Watch(ctx context.Context, onReload func(error)) error {
+	var watchable []WatchableSource
+	for _, entry := range c.sources {
+		if ws, ok := entry.source.(WatchableSource); ok {
+			watchable = append(watchable, ws)
+		}
+	}
+
+	if len(watchable) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(c.withLookupEnv(ctx))
+	defer cancel()
+
+	changes := make(chan struct{}, 1)
+	errs := make(chan error, len(watchable))
+
+	for _, ws := range watchable {
+		go func(ws WatchableSource) {
+			errs <- ws.Watch(ctx, func() {
+				select {
+				case changes <- struct{}{}:
+				default:
+				}
+			})
+		}(ws)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errs:
+			if err != nil && ctx.Err() == nil {
+				return err
+			}
+		case <-changes:
+			err := c.ReloadContext(ctx)
+			if onReload != nil {
+				onReload(err)
+			}
+		}
+	}
+}
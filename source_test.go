@@ -0,0 +1,183 @@
+package configo_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/affanshahid/configo"
+	"github.com/stretchr/testify/assert"
+)
+
+type staticSource map[string]interface{}
+
+func (s staticSource) Load(ctx context.Context) (map[string]interface{}, error) {
+	return map[string]interface{}(s), nil
+}
+
+func (s staticSource) String() string {
+	return "static"
+}
+
+type watchableSource struct {
+	values  chan map[string]interface{}
+	current map[string]interface{}
+}
+
+func (s *watchableSource) Load(ctx context.Context) (map[string]interface{}, error) {
+	return map[string]interface{}{"p1": s.current["p1"]}, nil
+}
+
+func (s *watchableSource) Watch(ctx context.Context, onChange func()) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case v := <-s.values:
+			s.current = v
+			onChange()
+		}
+	}
+}
+
+func TestSourcePrecedence(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                p1: default
+                p2: default
+                p3: default
+                p4: ENV_OVERRIDE_P4
+            `),
+		},
+		"production.yml": {
+			Data: []byte(`
+                p2: production
+            `),
+		},
+		"env.yml": {
+			Data: []byte(`
+                p4: ENV_OVERRIDE_P4
+            `),
+		},
+	}
+
+	t.Setenv("ENV_OVERRIDE_P4", "env")
+
+	config, err := configo.NewConfig(
+		dir,
+		configo.WithDeployment("production"),
+		configo.WithSource(staticSource{"p1": "before", "p5": "before"}, configo.BeforeFiles),
+		configo.WithSource(staticSource{"p2": "template", "p3": "template"}, configo.AfterTemplate("default")),
+		configo.WithSource(staticSource{"p4": "after-files"}, configo.AfterFiles),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, "default", config.MustGetString("p1"))
+	assert.Equal(t, "production", config.MustGetString("p2"))
+	assert.Equal(t, "template", config.MustGetString("p3"))
+	assert.Equal(t, "env", config.MustGetString("p4"))
+	assert.Equal(t, "before", config.MustGetString("p5"))
+
+	pos, _ := config.Position("p3")
+	assert.Equal(t, "static", pos.File)
+
+	config, err = configo.NewConfig(
+		dir,
+		configo.WithSource(staticSource{"p4": "after-env"}, configo.AfterEnv),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, "after-env", config.MustGetString("p4"))
+}
+
+func TestSourceUnknownTemplate(t *testing.T) {
+	config, err := configo.NewConfig(
+		fstest.MapFS{},
+		configo.WithSource(staticSource{}, configo.AfterTemplate("{unknown}")),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.NotNil(t, err)
+}
+
+func TestWatch(t *testing.T) {
+	source := &watchableSource{
+		values:  make(chan map[string]interface{}),
+		current: map[string]interface{}{"p1": "foo"},
+	}
+
+	config, err := configo.NewConfig(fstest.MapFS{}, configo.WithSource(source, configo.AfterFiles))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, "foo", config.MustGetString("p1"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan error)
+	go config.Watch(ctx, func(err error) {
+		reloaded <- err
+	})
+
+	source.values <- map[string]interface{}{"p1": "bar"}
+
+	select {
+	case err := <-reloaded:
+		assert.Nilf(t, err, "err should be nil")
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+
+	assert.Equal(t, "bar", config.MustGetString("p1"))
+}
+
+func TestWatchKeyPerFile(t *testing.T) {
+	mount := t.TempDir()
+	err := os.WriteFile(mount+"/p1", []byte("foo"), 0644)
+	assert.Nilf(t, err, "err should be nil")
+
+	source := configo.KeyPerFileSource(
+		os.DirFS(mount),
+		"",
+		configo.WithKeyPerFilePollInterval(10*time.Millisecond),
+	)
+
+	config, err := configo.NewConfig(fstest.MapFS{}, configo.WithKeyPerFile(source))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reloaded := make(chan error, 1)
+	go config.Watch(ctx, func(err error) {
+		reloaded <- err
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	err = os.WriteFile(mount+"/p1", []byte("bar"), 0644)
+	assert.Nilf(t, err, "err should be nil")
+
+	select {
+	case err := <-reloaded:
+		assert.Nilf(t, err, "err should be nil")
+	case <-time.After(5 * time.Second):
+		t.Fatal("config was not reloaded")
+	}
+
+	assert.Equal(t, "bar", config.MustGetString("p1"))
+}
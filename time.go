@@ -0,0 +1,270 @@
+package configo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/cast"
+)
+
+// timeFormat controls how values are converted into durations and times,
+// zero fields are inherited from the format of the parent paths and the Config
+type timeFormat struct {
+	durationUnit time.Duration
+	layouts      []string
+	location     *time.Location
+}
+
+// WithDurationUnit sets the unit of durations written as bare numbers, i.e `timeout: 30`
+// with `time.Second` is 30 seconds (defaults to nanoseconds).
+// The unit applies to the given paths and the values under them, or to every path if none are given
+func WithDurationUnit(unit time.Duration, paths ...string) ConfigOption {
+	return withTimeFormat(paths, func(f *timeFormat) {
+		f.durationUnit = unit
+	})
+}
+
+// WithTimeLayouts sets the layouts, as understood by `time.Parse`, tried in order when strings are
+// converted into times. The layouts apply to the given paths and the values under them,
+// or to every path if none are given. Without layouts a set of common formats is tried
+func WithTimeLayouts(layouts []string, paths ...string) ConfigOption {
+	return withTimeFormat(paths, func(f *timeFormat) {
+		f.layouts = append([]string(nil), layouts...)
+	})
+}
+
+// WithTimeLocation sets the time zone of times written without one, such as `2024-01-31 12:00`
+// or TOML local date-times (defaults to UTC). The time zone applies to the given paths and
+// the values under them, or to every path if none are given
+func WithTimeLocation(location *time.Location, paths ...string) ConfigOption {
+	return withTimeFormat(paths, func(f *timeFormat) {
+		f.location = location
+	})
+}
+
+func withTimeFormat(paths []string, set func(f *timeFormat)) ConfigOption {
+	return func(c *Config) {
+		if len(paths) == 0 {
+			paths = []string{""}
+		}
+
+		for _, path := range paths {
+			path = canonicalPath(path)
+
+			f, found := c.timeFormats[path]
+			if !found {
+				f = &timeFormat{}
+				c.timeFormats[path] = f
+			}
+			set(f)
+		}
+	}
+}
+
+// timeFormat merges the formats of the Config and of the path and its parents
+func (c *Config) timeFormat(path string) timeFormat {
+	format := timeFormat{durationUnit: time.Nanosecond, location: time.UTC}
+
+	if len(c.timeFormats) == 0 {
+		return format
+	}
+
+	merge := func(path string) {
+		f, found := c.timeFormats[path]
+		if !found {
+			return
+		}
+		if f.durationUnit != 0 {
+			format.durationUnit = f.durationUnit
+		}
+		if f.layouts != nil {
+			format.layouts = f.layouts
+		}
+		if f.location != nil {
+			format.location = f.location
+		}
+	}
+
+	merge("")
+
+	p, err := compilePath(path)
+	if err != nil {
+		return format
+	}
+
+	for i := 1; i <= len(p.keys); i++ {
+		merge(JoinPath(p.keys[:i]...))
+	}
+
+	return format
+}
+
+// durationUnitRegexp matches the days and weeks of extended durations such as `1d12h` or `2w`
+var durationUnitRegexp = regexp.MustCompile(`([0-9]*\.?[0-9]+)([dw])`)
+
+// isoDurationRegexp matches ISO 8601 durations such as `P1DT2H30M` or `PT0.5S`
+var isoDurationRegexp = regexp.MustCompile(
+	`^([-+])?P(?:([0-9.,]+)Y)?(?:([0-9.,]+)M)?(?:([0-9.,]+)W)?(?:([0-9.,]+)D)?(?:T(?:([0-9.,]+)H)?(?:([0-9.,]+)M)?(?:([0-9.,]+)S)?)?$`,
+)
+
+// parseDuration converts the value into a duration. Strings are Go durations which may also use
+// `d` for days and `w` for weeks, ISO 8601 durations or bare numbers, which are multiples of unit
+func parseDuration(value interface{}, unit time.Duration) (time.Duration, error) {
+	switch v := value.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		s := strings.TrimSpace(v)
+
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return scaleIntDuration(n, unit)
+		}
+
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return scaleDuration(f, unit)
+		}
+
+		if strings.HasPrefix(strings.TrimLeft(s, "+-"), "P") {
+			return parseISODuration(s)
+		}
+
+		var err error
+		s = durationUnitRegexp.ReplaceAllStringFunc(s, func(match string) string {
+			n, parseErr := strconv.ParseFloat(match[:len(match)-1], 64)
+			if parseErr != nil {
+				err = parseErr
+				return match
+			}
+
+			hours := 24.0
+			if match[len(match)-1] == 'w' {
+				hours = 7 * 24
+			}
+			return strconv.FormatFloat(n*hours, 'f', -1, 64) + "h"
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, errors.New("expected a duration such as 1h30m, 2d, P1DT2H or a number")
+		}
+		return d, nil
+	case int, int8, int16, int32, int64:
+		n, err := cast.ToInt64E(v)
+		if err != nil {
+			return 0, err
+		}
+		return scaleIntDuration(n, unit)
+	case uint, uint8, uint16, uint32, uint64:
+		n, err := cast.ToUint64E(v)
+		if err != nil {
+			return 0, err
+		}
+		if n > math.MaxInt64 {
+			return 0, errDurationOverflow
+		}
+		return scaleIntDuration(int64(n), unit)
+	}
+
+	f, err := cast.ToFloat64E(value)
+	if err != nil {
+		return 0, err
+	}
+
+	return scaleDuration(f, unit)
+}
+
+var errDurationOverflow = errors.New("duration overflows")
+
+// scaleIntDuration multiplies whole numbers exactly, floats lose precision above 2^53
+func scaleIntDuration(n int64, unit time.Duration) (time.Duration, error) {
+	d := n * int64(unit)
+	if n != 0 && d/n != int64(unit) {
+		return 0, errDurationOverflow
+	}
+
+	return time.Duration(d), nil
+}
+
+// scaleDuration multiplies fractional numbers
+func scaleDuration(n float64, unit time.Duration) (time.Duration, error) {
+	d := n * float64(unit)
+	// float64(math.MaxInt64) is 2^63, which does not fit
+	if d >= math.MaxInt64 || d < math.MinInt64 || math.IsNaN(d) {
+		return 0, errDurationOverflow
+	}
+
+	return time.Duration(d), nil
+}
+
+// parseISODuration parses ISO 8601 durations, years and months are rejected
+// since they do not have a fixed length
+func parseISODuration(s string) (time.Duration, error) {
+	match := isoDurationRegexp.FindStringSubmatch(s)
+	if match == nil || s == "P" || strings.HasSuffix(s, "T") || strings.TrimLeft(s, "+-") == "P" {
+		return 0, errors.New("invalid ISO 8601 duration")
+	}
+
+	if match[2] != "" || match[3] != "" {
+		return 0, errors.New("ISO 8601 durations with years or months have no fixed length")
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+	var total float64
+	for i, unit := range units {
+		component := match[i+4]
+		if component == "" {
+			continue
+		}
+
+		n, err := strconv.ParseFloat(strings.Replace(component, ",", ".", 1), 64)
+		if err != nil {
+			return 0, errors.New("invalid ISO 8601 duration")
+		}
+		total += n * float64(unit)
+	}
+
+	if match[1] == "-" {
+		total = -total
+	}
+
+	return scaleDuration(total, 1)
+}
+
+// parseTime converts the value into a time. Strings are parsed with the layouts of the format,
+// or with common layouts if there are none
+func parseTime(value interface{}, format timeFormat) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case toml.LocalDateTime:
+		return v.AsTime(format.location), nil
+	case toml.LocalDate:
+		return v.AsTime(format.location), nil
+	case toml.LocalTime:
+		return time.Time{}, errors.New("a time of day has no date")
+	case string:
+		if len(format.layouts) == 0 {
+			break
+		}
+
+		for _, layout := range format.layouts {
+			t, err := time.ParseInLocation(layout, strings.TrimSpace(v), format.location)
+			if err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("expected a time in one of the layouts %s", strings.Join(format.layouts, ", "))
+	}
+
+	return cast.ToTimeInDefaultLocationE(value, format.location)
+}
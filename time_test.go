@@ -0,0 +1,175 @@
+package configo_test
+
+import (
+	"math"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/affanshahid/configo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDurationFormats(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                timeout: 30
+                retention: 2w
+                grace: 1d12h
+                fraction: 1.5d
+                iso:
+                    mixed: P1DT2H30M
+                    fraction: PT0.5S
+                    negative: -PT1M
+                    years: P1Y
+                    empty: PT
+                invalid: soon
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, 30*time.Nanosecond, config.MustGetDuration("timeout"))
+	assert.Equal(t, 14*24*time.Hour, config.MustGetDuration("retention"))
+	assert.Equal(t, 36*time.Hour, config.MustGetDuration("grace"))
+	assert.Equal(t, 36*time.Hour, config.MustGetDuration("fraction"))
+	assert.Equal(t, 26*time.Hour+30*time.Minute, config.MustGetDuration("iso.mixed"))
+	assert.Equal(t, 500*time.Millisecond, config.MustGetDuration("iso.fraction"))
+	assert.Equal(t, -time.Minute, config.MustGetDuration("iso.negative"))
+
+	_, err = config.GetDuration("iso.years")
+	assert.ErrorContains(t, err, "no fixed length")
+
+	_, err = config.GetDuration("iso.empty")
+	assert.ErrorContains(t, err, `unable to convert "PT" at iso.empty to a duration`)
+
+	_, err = config.GetDuration("invalid")
+	assert.ErrorContains(t, err, `unable to convert "soon" at invalid to a duration`)
+}
+
+func TestDurationPrecision(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                precise: 9007199254740993
+                max: 9223372036854775807
+                quoted: "9223372036854775807"
+                tooLarge: 9223372036854775808
+                seconds:
+                    max: 9223372036
+                    overflow: 9223372037
+                    fraction: 9223372036.9
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir, configo.WithDurationUnit(time.Second, "seconds"))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, time.Duration(9007199254740993), config.MustGetDuration("precise"))
+	assert.Equal(t, time.Duration(math.MaxInt64), config.MustGetDuration("max"))
+	assert.Equal(t, time.Duration(math.MaxInt64), config.MustGetDuration("quoted"))
+	assert.Equal(t, 9223372036*time.Second, config.MustGetDuration("seconds.max"))
+
+	for _, path := range []string{"tooLarge", "seconds.overflow", "seconds.fraction"} {
+		_, err = config.GetDuration(path)
+		assert.ErrorContains(t, err, "duration overflows", path)
+	}
+}
+
+func TestWithDurationUnit(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                timeout: 30
+                retention: 2w
+                http:
+                    timeout: 250
+                    backoff: [100, 2s]
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(
+		dir,
+		configo.WithDurationUnit(time.Second),
+		configo.WithDurationUnit(time.Millisecond, "http"),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, 30*time.Second, config.MustGetDuration("timeout"))
+	assert.Equal(t, 250*time.Millisecond, config.MustGetDuration("http.timeout"))
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 2 * time.Second}, config.MustGetDurationSlice("http.backoff"))
+	assert.Equal(t, 14*24*time.Hour, config.MustGetDuration("retention"))
+
+	sub, err := config.Sub("http")
+	assert.Nilf(t, err, "err should be nil")
+	assert.Equal(t, 250*time.Millisecond, sub.MustGetDuration("timeout"))
+}
+
+func TestWithTimeLayouts(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.Nilf(t, err, "err should be nil")
+
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                release: 31/01/2024 12:00
+                started: 2024-01-31 12:00
+                grace: 1d12h
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(
+		dir,
+		configo.WithTimeLayouts([]string{"2006-01-02 15:04"}),
+		configo.WithTimeLayouts([]string{"02/01/2006 15:04"}, "release"),
+		configo.WithTimeLocation(berlin, "started"),
+	)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, time.Date(2024, 1, 31, 12, 0, 0, 0, time.UTC), config.MustGetTime("release"))
+	assert.Equal(t, time.Date(2024, 1, 31, 12, 0, 0, 0, berlin), config.MustGetTime("started"))
+
+	_, err = config.GetTime("grace")
+	assert.ErrorContains(t, err, "expected a time in one of the layouts 2006-01-02 15:04")
+}
+
+func TestGetTimeTOMLLocal(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.Nilf(t, err, "err should be nil")
+
+	dir := fstest.MapFS{
+		"default.toml": {
+			Data: []byte("deadline = 2024-01-31T12:00:00\nday = 2024-01-31\nalarm = 07:30:00\n"),
+		},
+	}
+
+	config, err := configo.NewConfig(dir, configo.WithTimeLocation(berlin, "deadline"))
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, time.Date(2024, 1, 31, 12, 0, 0, 0, berlin), config.MustGetTime("deadline"))
+	assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), config.MustGetTime("day"))
+
+	_, err = config.GetTime("alarm")
+	assert.ErrorContains(t, err, "a time of day has no date")
+}
@@ -0,0 +1,369 @@
+package configo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// byteUnits maps the lower case units of byte sizes to their multiplier,
+// SI units are powers of 1000 and IEC units are powers of 1024
+var byteUnits = map[string]uint64{
+	"":    1,
+	"b":   1,
+	"k":   1000,
+	"kb":  1000,
+	"m":   1000 * 1000,
+	"mb":  1000 * 1000,
+	"g":   1000 * 1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"t":   1000 * 1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"p":   1000 * 1000 * 1000 * 1000 * 1000,
+	"pb":  1000 * 1000 * 1000 * 1000 * 1000,
+	"ki":  1 << 10,
+	"kib": 1 << 10,
+	"mi":  1 << 20,
+	"mib": 1 << 20,
+	"gi":  1 << 30,
+	"gib": 1 << 30,
+	"ti":  1 << 40,
+	"tib": 1 << 40,
+	"pi":  1 << 50,
+	"pib": 1 << 50,
+}
+
+var byteSizeRegexp = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*([A-Za-z]*)$`)
+
+// conversionError quotes the value and the path which could not be converted
+func conversionError(path string, value interface{}, kind string, err error) error {
+	if err != nil {
+		return fmt.Errorf("unable to convert %q at %s to %s: %w", fmt.Sprint(value), path, kind, err)
+	}
+
+	return fmt.Errorf("unable to convert %q at %s to %s", fmt.Sprint(value), path, kind)
+}
+
+// parseByteSize parses sizes such as `512MiB`, `1.5 GB` or `1024`, bare numbers are bytes
+func parseByteSize(value interface{}) (uint64, error) {
+	switch v := value.(type) {
+	case string:
+		match := byteSizeRegexp.FindStringSubmatch(strings.TrimSpace(v))
+		if match == nil {
+			return 0, errors.New("expected a number followed by a unit such as KB or MiB")
+		}
+
+		multiplier, found := byteUnits[strings.ToLower(match[2])]
+		if !found {
+			return 0, fmt.Errorf("unknown unit %s", match[2])
+		}
+
+		if !strings.Contains(match[1], ".") {
+			n, err := strconv.ParseUint(match[1], 10, 64)
+			if err != nil || n > math.MaxUint64/multiplier {
+				return 0, errors.New("size overflows uint64")
+			}
+			return n * multiplier, nil
+		}
+
+		f, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return 0, err
+		}
+
+		size := f * float64(multiplier)
+		if size >= math.MaxUint64 {
+			return 0, errors.New("size overflows uint64")
+		}
+		return uint64(size), nil
+	case float32, float64:
+		f := cast.ToFloat64(v)
+		if f < 0 || f != math.Trunc(f) {
+			return 0, errors.New("sizes are whole non negative numbers")
+		}
+	}
+
+	return cast.ToUint64E(value)
+}
+
+// parseFileMode parses permissions such as `0644`, `0o755` or `644`, strings are octal
+// and numbers are taken as they are since YAML already reads `0644` as an octal number
+func parseFileMode(value interface{}) (os.FileMode, error) {
+	var mode uint64
+
+	if s, ok := value.(string); ok {
+		s = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "0o"), "0O")
+
+		m, err := strconv.ParseUint(s, 8, 32)
+		if err != nil {
+			return 0, errors.New("expected octal permissions such as 0644")
+		}
+		mode = m
+	} else {
+		m, err := cast.ToUint64E(value)
+		if err != nil {
+			return 0, err
+		}
+		mode = m
+	}
+
+	if mode > 0o7777 {
+		return 0, errors.New("permissions are at most 07777")
+	}
+
+	fileMode := os.FileMode(mode & 0o777)
+	if mode&0o4000 != 0 {
+		fileMode |= os.ModeSetuid
+	}
+	if mode&0o2000 != 0 {
+		fileMode |= os.ModeSetgid
+	}
+	if mode&0o1000 != 0 {
+		fileMode |= os.ModeSticky
+	}
+
+	return fileMode, nil
+}
+
+// GetByteSize returns the value at the given path as a number of bytes.
+// Strings take a unit, `KB`, `MB`, `GB`, `TB` and `PB` are powers of 1000 and
+// `KiB`, `MiB`, `GiB`, `TiB` and `PiB` powers of 1024, numbers are bytes
+func (c *Config) GetByteSize(path string) (uint64, error) {
+	out, err := c.lookup(path)
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := parseByteSize(out)
+	if err != nil {
+		return 0, c.redactError(path, conversionError(path, out, "a byte size", err))
+	}
+
+	return v, nil
+}
+
+// GetURL returns the value at the given path as a URL
+func (c *Config) GetURL(path string) (*url.URL, error) {
+	out, err := c.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := out.(string)
+	if !ok {
+		return nil, c.redactError(path, conversionError(path, out, "a URL", nil))
+	}
+
+	v, err := url.Parse(s)
+	if err != nil {
+		return nil, c.redactError(path, conversionError(path, out, "a URL", errors.Unwrap(err)))
+	}
+
+	return v, nil
+}
+
+// GetIP returns the value at the given path as an IPv4 or IPv6 address
+func (c *Config) GetIP(path string) (net.IP, error) {
+	out, err := c.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := out.(string)
+	if !ok {
+		return nil, c.redactError(path, conversionError(path, out, "an IP address", nil))
+	}
+
+	v, err := netip.ParseAddr(strings.TrimSpace(s))
+	if err == nil && v.Zone() != "" {
+		err = errors.New("zones are not supported")
+	}
+	if err != nil {
+		return nil, c.redactError(path, conversionError(path, out, "an IP address", err))
+	}
+
+	// the 16 byte form, as returned by net.ParseIP
+	return net.IP(v.AsSlice()).To16(), nil
+}
+
+// GetPrefix returns the value at the given path as an IP network in CIDR notation, i.e `10.0.0.0/8`
+func (c *Config) GetPrefix(path string) (netip.Prefix, error) {
+	out, err := c.lookup(path)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	s, ok := out.(string)
+	if !ok {
+		return netip.Prefix{}, c.redactError(path, conversionError(path, out, "a CIDR prefix", nil))
+	}
+
+	v, err := netip.ParsePrefix(strings.TrimSpace(s))
+	if err != nil {
+		return netip.Prefix{}, c.redactError(path, conversionError(path, out, "a CIDR prefix", err))
+	}
+
+	return v, nil
+}
+
+// GetRegexp returns the value at the given path compiled as a regular expression
+func (c *Config) GetRegexp(path string) (*regexp.Regexp, error) {
+	out, err := c.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := out.(string)
+	if !ok {
+		return nil, c.redactError(path, conversionError(path, out, "a regular expression", nil))
+	}
+
+	v, err := regexp.Compile(s)
+	if err != nil {
+		return nil, c.redactError(path, conversionError(path, out, "a regular expression", err))
+	}
+
+	return v, nil
+}
+
+// GetLocation returns the value at the given path as a time zone, i.e `Europe/Berlin` or `UTC`
+func (c *Config) GetLocation(path string) (*time.Location, error) {
+	out, err := c.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s, ok := out.(string)
+	if !ok {
+		return nil, c.redactError(path, conversionError(path, out, "a time zone", nil))
+	}
+
+	v, err := time.LoadLocation(s)
+	if err != nil {
+		return nil, c.redactError(path, conversionError(path, out, "a time zone", err))
+	}
+
+	return v, nil
+}
+
+// GetFileMode returns the value at the given path as file permissions, strings such as `0644` are octal
+func (c *Config) GetFileMode(path string) (os.FileMode, error) {
+	out, err := c.lookup(path)
+	if err != nil {
+		return 0, err
+	}
+
+	v, err := parseFileMode(out)
+	if err != nil {
+		return 0, c.redactError(path, conversionError(path, out, "a file mode", err))
+	}
+
+	return v, nil
+}
+
+// GetDurationSlice returns the value at the given path as a slice of durations, see `GetDuration`
+func (c *Config) GetDurationSlice(path string) ([]time.Duration, error) {
+	out, err := c.lookup(path)
+	if err != nil {
+		return nil, err
+	}
+
+	items, ok := out.([]interface{})
+	if !ok {
+		return nil, c.redactError(path, conversionError(path, out, "a slice of durations", nil))
+	}
+
+	v := make([]time.Duration, len(items))
+	for i, item := range items {
+		itemPath := joinKeyPath(canonicalPath(path), strconv.Itoa(i))
+
+		v[i], err = parseDuration(item, c.timeFormat(itemPath).durationUnit)
+		if err != nil {
+			return nil, c.redactError(path, conversionError(itemPath, item, "a duration", err))
+		}
+	}
+
+	return v, nil
+}
+
+// MustGetByteSize is the same as `GetByteSize` except it panics in case of an error
+func (c *Config) MustGetByteSize(path string) uint64 {
+	v, err := c.GetByteSize(path)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// MustGetURL is the same as `GetURL` except it panics in case of an error
+func (c *Config) MustGetURL(path string) *url.URL {
+	v, err := c.GetURL(path)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// MustGetIP is the same as `GetIP` except it panics in case of an error
+func (c *Config) MustGetIP(path string) net.IP {
+	v, err := c.GetIP(path)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// MustGetPrefix is the same as `GetPrefix` except it panics in case of an error
+func (c *Config) MustGetPrefix(path string) netip.Prefix {
+	v, err := c.GetPrefix(path)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// MustGetRegexp is the same as `GetRegexp` except it panics in case of an error
+func (c *Config) MustGetRegexp(path string) *regexp.Regexp {
+	v, err := c.GetRegexp(path)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// MustGetLocation is the same as `GetLocation` except it panics in case of an error
+func (c *Config) MustGetLocation(path string) *time.Location {
+	v, err := c.GetLocation(path)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// MustGetFileMode is the same as `GetFileMode` except it panics in case of an error
+func (c *Config) MustGetFileMode(path string) os.FileMode {
+	v, err := c.GetFileMode(path)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// MustGetDurationSlice is the same as `GetDurationSlice` except it panics in case of an error
+func (c *Config) MustGetDurationSlice(path string) []time.Duration {
+	v, err := c.GetDurationSlice(path)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
@@ -0,0 +1,275 @@
+package configo_test
+
+import (
+	"net"
+	"net/netip"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/affanshahid/configo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetByteSize(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                sizes:
+                    bytes: 1024
+                    iec: 512MiB
+                    si: 1.5 GB
+                    small: 4k
+                    invalid: 12 parsecs
+                    negative: -1
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, uint64(1024), config.MustGetByteSize("sizes.bytes"))
+	assert.Equal(t, uint64(512<<20), config.MustGetByteSize("sizes.iec"))
+	assert.Equal(t, uint64(1_500_000_000), config.MustGetByteSize("sizes.si"))
+	assert.Equal(t, uint64(4000), config.MustGetByteSize("sizes.small"))
+
+	_, err = config.GetByteSize("sizes.invalid")
+	assert.ErrorContains(t, err, `"12 parsecs" at sizes.invalid`)
+
+	_, err = config.GetByteSize("sizes.negative")
+	assert.NotNil(t, err)
+}
+
+func TestGetURL(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                endpoint: https://api.example.com:8443/v1?debug=true
+                badEndpoint: "http://[::1"
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	u := config.MustGetURL("endpoint")
+	assert.Equal(t, "api.example.com:8443", u.Host)
+	assert.Equal(t, "/v1", u.Path)
+	assert.Equal(t, "true", u.Query().Get("debug"))
+
+	_, err = config.GetURL("badEndpoint")
+	assert.ErrorContains(t, err, `"http://[::1" at badEndpoint`)
+}
+
+func TestGetIP(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                ips:
+                    v4: 10.0.0.1
+                    v6: "::1"
+                    invalid: 10.0.0.256
+                    zoned: "fe80::1%eth0"
+                    number: 167772161
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, net.ParseIP("10.0.0.1"), config.MustGetIP("ips.v4"))
+	assert.Equal(t, net.IPv6loopback, config.MustGetIP("ips.v6"))
+
+	_, err = config.GetIP("ips.invalid")
+	assert.ErrorContains(t, err, `"10.0.0.256" at ips.invalid`)
+	assert.ErrorContains(t, err, "value >255")
+
+	_, err = config.GetIP("ips.zoned")
+	assert.ErrorContains(t, err, "zones are not supported")
+
+	_, err = config.GetIP("ips.number")
+	assert.EqualError(t, err, `unable to convert "167772161" at ips.number to an IP address`)
+}
+
+func TestGetPrefix(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                network: 10.0.0.0/8
+                address: 10.0.0.1
+                number: 167772161
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, netip.MustParsePrefix("10.0.0.0/8"), config.MustGetPrefix("network"))
+
+	_, err = config.GetPrefix("address")
+	assert.ErrorContains(t, err, `"10.0.0.1" at address`)
+	assert.ErrorContains(t, err, "no '/'")
+
+	_, err = config.GetPrefix("number")
+	assert.EqualError(t, err, `unable to convert "167772161" at number to a CIDR prefix`)
+}
+
+func TestGetRegexp(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                pattern: ^api-[0-9]+$
+                badPattern: "(unclosed"
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.True(t, config.MustGetRegexp("pattern").MatchString("api-42"))
+
+	_, err = config.GetRegexp("badPattern")
+	assert.ErrorContains(t, err, `"(unclosed" at badPattern`)
+}
+
+func TestGetLocation(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                zone: Europe/Berlin
+                badZone: Mars/Olympus
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, "Europe/Berlin", config.MustGetLocation("zone").String())
+
+	_, err = config.GetLocation("badZone")
+	assert.ErrorContains(t, err, `"Mars/Olympus" at badZone`)
+	assert.ErrorContains(t, err, "unknown time zone")
+}
+
+func TestGetFileMode(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                modes:
+                    octal: 0644
+                    string: "0o755"
+                    sticky: "1777"
+                    invalid: "0999"
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, os.FileMode(0o644), config.MustGetFileMode("modes.octal"))
+	assert.Equal(t, os.FileMode(0o755), config.MustGetFileMode("modes.string"))
+	assert.Equal(t, os.FileMode(0o777)|os.ModeSticky, config.MustGetFileMode("modes.sticky"))
+
+	_, err = config.GetFileMode("modes.invalid")
+	assert.ErrorContains(t, err, `"0999" at modes.invalid`)
+}
+
+func TestGetDurationSlice(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                backoff: [1s, 5s, 1m]
+                badBackoff: [1s, soon]
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	assert.Equal(t, []time.Duration{time.Second, 5 * time.Second, time.Minute}, config.MustGetDurationSlice("backoff"))
+
+	_, err = config.GetDurationSlice("badBackoff")
+	assert.ErrorContains(t, err, `"soon" at badBackoff.1`)
+}
+
+func TestRichTypeSecretErrors(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                password: hunter2
+                secret: [password]
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	_, err = config.GetByteSize("password")
+	assert.NotNil(t, err)
+	assert.NotContains(t, err.Error(), "hunter2")
+}
+
+func TestGlobalRichTypes(t *testing.T) {
+	dir := fstest.MapFS{
+		"default.yml": {
+			Data: []byte(`
+                size: 512MiB
+            `),
+		},
+	}
+
+	config, err := configo.NewConfig(dir)
+	assert.Nilf(t, err, "err should be nil")
+
+	err = config.Initialize()
+	assert.Nilf(t, err, "err should be nil")
+
+	previous := configo.Global()
+	defer configo.SetGlobal(previous)
+
+	configo.SetGlobal(config)
+
+	assert.Equal(t, uint64(512<<20), configo.MustGetByteSize("size"))
+
+	configo.SetGlobal(nil)
+
+	_, err = configo.GetURL("endpoint")
+	assert.ErrorIs(t, err, configo.ErrNotInitialized)
+}